@@ -0,0 +1,172 @@
+package webapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/pprof"
+	"path"
+	"reflect"
+	"strconv"
+)
+
+//ErrInsecureAdmin The admin endpoints are enabled without auth middleware
+var ErrInsecureAdmin = errors.New("the admin endpoints require auth middleware (set InsecureAdmin of Config to allow anonymous access)")
+
+type (
+	//healthCheck named health check function
+	healthCheck struct {
+		name  string
+		check func(context.Context) error
+	}
+)
+
+//AddHealthCheck Add health check which will be reported by the health endpoint of admin
+func (host *Host) AddHealthCheck(name string, check func(context.Context) error) *Host {
+	host.checks = append(host.checks, healthCheck{
+		name:  name,
+		check: check,
+	})
+	return host
+}
+
+//EnableAdmin Register the admin endpoints for runtime introspection under basepath, they must be protected
+//by the auth middleware (nothing will be registered if it is nil unless InsecureAdmin of Config is set).
+//
+//	[GET] /routes       route table
+//	[GET] /config       configuration snapshot
+//	[GET] /middlewares  global middleware chain
+//	[GET] /health       health checks (503 if any check fails)
+//	[GET] /states       states of middlewares which implement StateReporter
+//	[GET] /postman      Postman collection of routes
+//	[GET] /pprof/       runtime profiles
+func (host *Host) EnableAdmin(basepath string, auth Middleware) error {
+	var middlewares []Middleware
+	if auth != nil {
+		middlewares = append(middlewares, auth)
+	} else if !host.conf.InsecureAdmin {
+		host.mutex.Lock()
+		defer host.mutex.Unlock()
+		host.initCheck()
+		host.errList = append(host.errList, ErrInsecureAdmin)
+		return ErrInsecureAdmin
+	}
+	host.Group(basepath, func() {
+		host.AddEndpoint(http.MethodGet, "routes", func(ctx *Context) {
+			replyJSON(ctx, http.StatusOK, host.Report())
+		})
+		host.AddEndpoint(http.MethodGet, "config", func(ctx *Context) {
+			replyJSON(ctx, http.StatusOK, host.conf)
+		})
+		host.AddEndpoint(http.MethodGet, "middlewares", func(ctx *Context) {
+			host.mutex.RLock()
+			names := middlewareNames(host.mstack)
+			host.mutex.RUnlock()
+			replyJSON(ctx, http.StatusOK, names)
+		})
+		host.AddEndpoint(http.MethodGet, "health", func(ctx *Context) {
+			status, report := host.health(ctx.GetRequest().Context())
+			replyJSON(ctx, status, report)
+		})
+		host.AddEndpoint(http.MethodGet, "states", func(ctx *Context) {
+			replyJSON(ctx, http.StatusOK, host.states())
+		})
+		host.AddEndpoint(http.MethodGet, "postman", func(ctx *Context) {
+			var collection bytes.Buffer
+			if err := host.ExportPostman(&collection); err != nil {
+				ctx.ReplyError(http.StatusInternalServerError, err)
+				return
+			}
+			ctx.ResponseHeader().Set("Content-Type", "application/json")
+			ctx.Write(http.StatusOK, collection.Bytes())
+		})
+		host.AddEndpoint(http.MethodGet, "pprof/", func(ctx *Context) {
+			pprof.Index(ctx.GetResponseWriter(), ctx.GetRequest())
+		})
+		host.AddEndpoint(http.MethodGet, "pprof/cmdline", func(ctx *Context) {
+			pprof.Cmdline(ctx.GetResponseWriter(), ctx.GetRequest())
+		})
+		host.AddEndpoint(http.MethodGet, "pprof/profile", func(ctx *Context) {
+			pprof.Profile(ctx.GetResponseWriter(), ctx.GetRequest())
+		})
+		host.AddEndpoint(http.MethodGet, "pprof/symbol", func(ctx *Context) {
+			pprof.Symbol(ctx.GetResponseWriter(), ctx.GetRequest())
+		})
+		host.AddEndpoint(http.MethodPost, "pprof/symbol", func(ctx *Context) {
+			pprof.Symbol(ctx.GetResponseWriter(), ctx.GetRequest())
+		})
+		host.AddEndpoint(http.MethodGet, "pprof/trace", func(ctx *Context) {
+			pprof.Trace(ctx.GetResponseWriter(), ctx.GetRequest())
+		})
+		host.AddEndpoint(http.MethodGet, "pprof/{string}", func(ctx *Context) {
+			_, name := path.Split(ctx.GetRequest().URL.Path)
+			pprof.Handler(name).ServeHTTP(ctx.GetResponseWriter(), ctx.GetRequest())
+		})
+	}, middlewares...)
+	return nil
+}
+
+//health run all health checks
+func (host *Host) health(ctx context.Context) (int, map[string]string) {
+	status, report := http.StatusOK, make(map[string]string, len(host.checks))
+	for _, check := range host.checks {
+		var err error
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					err = errors.New("panic during health check")
+				}
+			}()
+			err = check.check(ctx)
+		}()
+		if err != nil {
+			status, report[check.name] = http.StatusServiceUnavailable, err.Error()
+		} else {
+			report[check.name] = "ok"
+		}
+	}
+	return status, report
+}
+
+//states collect states from the middlewares which implement StateReporter
+func (host *Host) states() map[string]interface{} {
+	host.mutex.RLock()
+	defer host.mutex.RUnlock()
+	var states = map[string]interface{}{}
+	var visited = map[interface{}]bool{}
+	var collect = func(middlewares []Middleware) {
+		for _, middleware := range middlewares {
+			reporter, isReporter := middleware.(StateReporter)
+			if !isReporter {
+				continue
+			}
+			if reflect.TypeOf(middleware).Comparable() {
+				if visited[middleware] {
+					continue
+				}
+				visited[middleware] = true
+			}
+			name := middlewareNames([]Middleware{middleware})[0]
+			if _, existed := states[name]; existed {
+				//the same type with different instances
+				name += "#" + strconv.Itoa(len(states))
+			}
+			states[name] = reporter.State()
+		}
+	}
+	collect(host.mstack)
+	for _, route := range host.routes {
+		collect(route.middlewares)
+	}
+	for _, tenant := range host.tenants {
+		collect(tenant.middlewares)
+	}
+	return states
+}
+
+//replyJSON reply with JSON serializer whatever the default content type is
+func replyJSON(ctx *Context, httpstatus int, obj interface{}) {
+	ctx.Serializer = Serializers["application/json"]
+	ctx.Reply(httpstatus, obj)
+}
@@ -0,0 +1,156 @@
+package webapi
+
+import (
+	"net/http"
+	"time"
+)
+
+//unsafeMethods the methods which are audited automatically
+var unsafeMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+type (
+	//AuditEvent The structured audit event of request
+	AuditEvent struct {
+		Time time.Time
+		//Actor The principal of request (see SetPrincipal)
+		Actor      interface{} `json:",omitempty"`
+		Tenant     string      `json:",omitempty"`
+		Method     string
+		Path       string
+		RemoteAddr string
+		//Route The pattern of the selected route (empty if not found)
+		Route      string `json:",omitempty"`
+		Controller string `json:",omitempty"`
+		Action     string `json:",omitempty"`
+		//Resources The path arguments of the route (e.g. resource IDs)
+		Resources []string `json:",omitempty"`
+		//Status The status code replied
+		Status int
+		//Success Whether the status code is less than 400
+		Success bool
+		//Diff The changes recorded by SetDiff
+		Diff *AuditDiff `json:",omitempty"`
+		//Fields The fields enriched by handlers
+		Fields map[string]interface{} `json:",omitempty"`
+
+		enriched bool
+	}
+
+	//AuditDiff The states before and after the change
+	AuditDiff struct {
+		Before interface{}
+		After  interface{}
+	}
+
+	//AuditSink The storage of audit events
+	AuditSink interface {
+		Audit(*AuditEvent) error
+	}
+
+	//AuditSinkFunc Function as AuditSink
+	AuditSinkFunc func(*AuditEvent) error
+
+	//AuditOption Option of Audit middleware
+	AuditOption func(*auditor)
+
+	//auditor the middleware which emits audit events
+	auditor struct {
+		sink    AuditSink
+		onError func(*Context, error)
+	}
+)
+
+//Audit The middleware which emits the audit events of the requests with unsafe methods (POST, PUT, PATCH and DELETE)
+//and the requests whose events are enriched by handlers (see ctx.AuditEvent) into sink after the requests are finished,
+//the errors of sink are reported to the PanicHandler (or the error handler) of host by default.
+func Audit(sink AuditSink, opts ...AuditOption) Middleware {
+	m := &auditor{sink: sink}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+//AuditErrors The errors of sink will be passed to handler instead of the PanicHandler of host
+func AuditErrors(handler func(ctx *Context, err error)) AuditOption {
+	return func(m *auditor) {
+		m.onError = handler
+	}
+}
+
+func (m *auditor) Invoke(ctx *Context, next HTTPHandler) {
+	event := &AuditEvent{
+		Time:       time.Now(),
+		Tenant:     ctx.tenant,
+		Method:     ctx.r.Method,
+		Path:       ctx.r.URL.Path,
+		RemoteAddr: ctx.r.RemoteAddr,
+	}
+	ctx.audit = event
+	//the event is emitted after the reply (including the panics recovered by host)
+	ctx.OnFinish(func(ctx *Context) {
+		if !event.enriched && !unsafeMethods[event.Method] {
+			return
+		}
+		event.Actor = ctx.principal
+		if route := ctx.route; route != nil {
+			event.Route, event.Controller, event.Action = route.Path, route.Controller, route.Action
+			if event.Resources == nil && len(ctx.routeArgs) > 0 {
+				//the arguments are reused by the later requests
+				event.Resources = append([]string{}, ctx.routeArgs...)
+			}
+		}
+		event.Status = ctx.statuscode
+		event.Success = event.Status > 0 && event.Status < http.StatusBadRequest
+		if err := m.sink.Audit(event); err != nil {
+			ctx.sinkError(m.onError, err)
+		}
+	})
+	next(ctx)
+}
+
+//Audit Audit via function
+func (f AuditSinkFunc) Audit(event *AuditEvent) error {
+	return f(event)
+}
+
+//AuditEvent The audit event of request which can be enriched by handlers (nil if Audit middleware is not used),
+//the methods of event are safe to call with nil
+func (ctx *Context) AuditEvent() *AuditEvent {
+	return ctx.audit
+}
+
+//Set Set the field of event, the event will be emitted whatever the method is
+func (event *AuditEvent) Set(key string, value interface{}) *AuditEvent {
+	if event != nil {
+		if event.Fields == nil {
+			event.Fields = map[string]interface{}{}
+		}
+		event.Fields[key] = value
+		event.enriched = true
+	}
+	return event
+}
+
+//SetResources Replace the resources of event (the path arguments by default)
+func (event *AuditEvent) SetResources(resources ...string) *AuditEvent {
+	if event != nil {
+		event.Resources = resources
+		event.enriched = true
+	}
+	return event
+}
+
+//SetDiff Record the states before and after the change
+func (event *AuditEvent) SetDiff(before interface{}, after interface{}) *AuditEvent {
+	if event != nil {
+		event.Diff = &AuditDiff{Before: before, After: after}
+		event.enriched = true
+	}
+	return event
+}
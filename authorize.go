@@ -0,0 +1,106 @@
+package webapi
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+//ErrNoAuthorizer The permissions are required but the authorizer is not set by host.SetAuthorizer
+var ErrNoAuthorizer = errors.New("the authorizer is not set")
+
+type (
+	//Authorizer Check whether the principal (set by ctx.SetPrincipal, nil if anonymous) is granted the permission,
+	//the request will be rejected with the error via the error handler (403 unless it carries the status code)
+	Authorizer func(ctx *Context, principal interface{}, permission string) error
+
+	//authorization the middleware which requires permissions
+	authorization struct {
+		permissions []string
+	}
+)
+
+//SetAuthorizer Set the authorizer which enforces the permissions before dispatching,
+//the permissions are declared by the permission tags (see PermissionTagName) of controller fields
+//(for all actions) and of the fields of query or body structures (for the action)
+func (host *Host) SetAuthorizer(authorizer Authorizer) *Host {
+	host.authorizer = authorizer
+	return host
+}
+
+//SetPrincipal Set the authenticated principal of request (e.g. by authentication middleware)
+func (ctx *Context) SetPrincipal(principal interface{}) {
+	ctx.principal = principal
+}
+
+//Principal The authenticated principal of request
+func (ctx *Context) Principal() interface{} {
+	return ctx.principal
+}
+
+//Authorize The middleware which requires all of the permissions via the authorizer of host (e.g. for AddEndpoint),
+//the actions of controllers should declare the permissions by tags instead
+func Authorize(permissions ...string) Middleware {
+	return &authorization{permissions: permissions}
+}
+
+func (m *authorization) Invoke(ctx *Context, next HTTPHandler) {
+	if ctx.authorize(m.permissions) {
+		next(ctx)
+	}
+}
+
+//authorized wrap the handler which requires the permissions
+func authorized(handler httpHandler, permissions []string) httpHandler {
+	if len(permissions) == 0 {
+		return handler
+	}
+	return func(ctx *Context, args ...string) {
+		if ctx.authorize(permissions) {
+			handler(ctx, args...)
+		}
+	}
+}
+
+//authorize check the permissions one by one, the error is replied if any of them is denied
+//(it is denied if no authorizer is set)
+func (ctx *Context) authorize(permissions []string) bool {
+	var err error
+	if ctx.host == nil || ctx.host.authorizer == nil {
+		err = ErrNoAuthorizer
+	} else {
+		for _, permission := range permissions {
+			if err = ctx.host.authorizer(ctx, ctx.principal, permission); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		if ctx.statuscode == 0 {
+			ctx.ReplyError(errorStatus(err, http.StatusForbidden), err)
+		}
+		return false
+	}
+	return true
+}
+
+//permissionsOf the permissions declared by the tags of structure fields (separated by comma)
+func (host *Host) permissionsOf(typ reflect.Type) (permissions []string) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+	for index := 0; index < typ.NumField(); index++ {
+		if tag, existed := typ.Field(index).Tag.Lookup(host.conf.PermissionTagName); existed {
+			for _, permission := range strings.Split(tag, ",") {
+				if permission = strings.TrimSpace(permission); len(permission) > 0 {
+					permissions = append(permissions, permission)
+				}
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,208 @@
+package webapi
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	//converter assign the text to value
+	converter func(reflect.Value, string) error
+
+	//bindingPlan the precompiled assignments of query structure
+	bindingPlan struct {
+		fields []fieldPlan
+	}
+
+	//fieldPlan the field index, the names looked up in order and the converter of field
+	fieldPlan struct {
+		index   []int
+		names   []string
+		convert converter
+	}
+)
+
+var (
+	//plans the cache of binding plans by structure type
+	plans sync.Map
+)
+
+//newParam create the parameter with the binding plan, converter and checker compiled
+func newParam(typ reflect.Type, isBody bool, isQuery bool) *param {
+	p := &param{Type: typ, isBody: isBody, isQuery: isQuery}
+	checked := typ
+	switch {
+	case isQuery:
+		p.plan = planOf(typ)
+		checked = reflect.PtrTo(typ)
+	case !isBody:
+		p.convert = converterOf(typ)
+	}
+	p.checker = checkerOf(checked)
+	return p
+}
+
+//planOf the binding plan of structure (the pointers are dereferenced)
+func planOf(typ reflect.Type) *bindingPlan {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if plan, existed := plans.Load(typ); existed {
+		return plan.(*bindingPlan)
+	}
+	plan := &bindingPlan{}
+	if typ.Kind() == reflect.Struct {
+		plan.fields = compileFields(typ, nil)
+	}
+	actual, _ := plans.LoadOrStore(typ, plan)
+	return actual.(*bindingPlan)
+}
+
+//compileFields collect the settable fields, the embedded structures are flattened
+func compileFields(typ reflect.Type, parent []int) (fields []fieldPlan) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		isStruct := field.Type.Kind() == reflect.Struct
+		if len(field.PkgPath) > 0 && !(field.Anonymous && isStruct) {
+			//unexported field cannot be set (except the exported fields of embedded structure)
+			continue
+		}
+		index := append(append([]int{}, parent...), i)
+		if isStruct {
+			fields = append(fields, compileFields(field.Type, index)...)
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if len(name) == 0 {
+			name = field.Name
+		}
+		if name == "-" {
+			continue
+		}
+		names := []string{name}
+		if lower := strings.ToLower(name); lower != name {
+			names = append(names, lower)
+		}
+		fields = append(fields, fieldPlan{index: index, names: names, convert: converterOf(field.Type)})
+	}
+	return
+}
+
+//bind assign the queries to structure value by plan
+func (plan *bindingPlan) bind(value reflect.Value, queries url.Values) {
+	for _, field := range plan.fields {
+		for _, name := range field.names {
+			if values, existed := queries[name]; existed {
+				var data string
+				if len(values) > 0 {
+					data = values[0]
+				}
+				field.convert(value.FieldByIndex(field.index), data)
+				break
+			}
+		}
+	}
+}
+
+//converterOf the converter of type (the kinds which are not supported will be ignored)
+func converterOf(typ reflect.Type) converter {
+	switch typ.Kind() {
+	case reflect.String:
+		return convertString
+	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
+		return convertInt
+	case reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uint8, reflect.Uint16:
+		return convertUint
+	case reflect.Float32, reflect.Float64:
+		return convertFloat
+	case reflect.Bool:
+		return convertBool
+	case reflect.Array:
+		elem := converterOf(typ.Elem())
+		return func(value reflect.Value, data string) error {
+			return convertArray(value, strings.Split(data, ","), elem)
+		}
+	case reflect.Slice:
+		elem := converterOf(typ.Elem())
+		return func(value reflect.Value, data string) error {
+			if array := strings.Split(data, ","); len(array[0]) > 0 {
+				value.Set(reflect.MakeSlice(typ, len(array), len(array)))
+				return convertArray(value, array, elem)
+			}
+			return nil
+		}
+	case reflect.Ptr:
+		elemType, elem := typ.Elem(), converterOf(typ.Elem())
+		return func(value reflect.Value, data string) error {
+			value.Set(reflect.New(elemType))
+			return elem(value.Elem(), data)
+		}
+	}
+	return convertNothing
+}
+
+func convertString(value reflect.Value, data string) error {
+	value.SetString(data)
+	return nil
+}
+
+func convertInt(value reflect.Value, data string) error {
+	val, _ := strconv.ParseInt(data, 10, 64)
+	value.SetInt(val)
+	return nil
+}
+
+func convertUint(value reflect.Value, data string) error {
+	val, _ := strconv.ParseUint(data, 10, 64)
+	value.SetUint(val)
+	return nil
+}
+
+func convertFloat(value reflect.Value, data string) error {
+	val, _ := strconv.ParseFloat(data, 64)
+	value.SetFloat(val)
+	return nil
+}
+
+func convertBool(value reflect.Value, data string) error {
+	value.SetBool(strings.ToLower(data) == "true")
+	return nil
+}
+
+func convertNothing(reflect.Value, string) error {
+	return nil
+}
+
+//convertArray assign the elements until either array or data is exhausted
+func convertArray(value reflect.Value, data []string, elem converter) (err error) {
+	cap := value.Len()
+	if cap > len(data) {
+		cap = len(data)
+	}
+	for index := 0; index < cap; index++ {
+		if err = elem(value.Index(index), data[index]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//checkerOf the index of Check method (func() error) in the method set of type, -1 if not found
+func checkerOf(typ reflect.Type) int {
+	method, existed := typ.MethodByName("Check")
+	if !existed {
+		return -1
+	}
+	in := method.Type.NumIn()
+	if typ.Kind() != reflect.Interface {
+		//receiver is excluded
+		in--
+	}
+	if in != 0 || method.Type.NumOut() != 1 || method.Type.Out(0) != types.Error {
+		return -1
+	}
+	return method.Index
+}
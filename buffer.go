@@ -0,0 +1,32 @@
+package webapi
+
+import (
+	"bytes"
+	"sync"
+)
+
+//maxPooledBuffer the buffers grown beyond it are dropped instead of being pooled
+const maxPooledBuffer = 64 << 10
+
+var (
+	//buffers the pool of response buffers used by StreamSerializer
+	buffers = sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
+)
+
+//getBuffer get an empty buffer from pool
+func getBuffer() *bytes.Buffer {
+	buffer := buffers.Get().(*bytes.Buffer)
+	buffer.Reset()
+	return buffer
+}
+
+//putBuffer put the buffer back into pool, the bytes of buffer must not be used anymore
+func putBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() <= maxPooledBuffer {
+		buffers.Put(buffer)
+	}
+}
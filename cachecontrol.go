@@ -0,0 +1,228 @@
+package webapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	//CacheControl Builder of Cache-Control header
+	CacheControl struct {
+		ctx        *Context
+		flags      []string
+		durations  map[string]time.Duration
+		directives []string
+	}
+
+	//cachePolicy the middleware which declares cache policy of routes
+	cachePolicy struct {
+		policy *CacheControl
+	}
+)
+
+//NewCacheControl Create Cache-Control builder for route policy (see CachePolicy)
+func NewCacheControl() *CacheControl {
+	return &CacheControl{durations: map[string]time.Duration{}}
+}
+
+//CacheControl Create Cache-Control builder of response, it starts with the policy declared by route
+func (ctx *Context) CacheControl() *CacheControl {
+	cache := NewCacheControl()
+	if ctx.cache != nil {
+		cache = ctx.cache.clone()
+	}
+	cache.ctx = ctx
+	return cache
+}
+
+//CachePolicy The cache policy of response declared by route or applied by handler (nil if not declared),
+//e.g. read by response cache middleware
+func (ctx *Context) CachePolicy() *CacheControl {
+	return ctx.cache
+}
+
+//CachePolicy The middleware which declares the cache policy of routes, handlers are still able to override it
+//via ctx.CacheControl() (or the Cache-Control header). The policy is only applied to 2xx and 304 responses
+//while the header is written, the others are replied with no-store
+func CachePolicy(policy *CacheControl) Middleware {
+	return &cachePolicy{policy: policy}
+}
+
+func (p *cachePolicy) Invoke(ctx *Context, next HTTPHandler) {
+	cache := p.policy.clone()
+	cache.ctx = ctx
+	ctx.cache, ctx.cachePending = cache, true
+	next(ctx)
+}
+
+//Public The response can be stored by shared caches
+func (cache *CacheControl) Public() *CacheControl {
+	return cache.flag("public", "private")
+}
+
+//Private The response cannot be stored by shared caches
+func (cache *CacheControl) Private() *CacheControl {
+	return cache.flag("private", "public")
+}
+
+//NoCache The response must be revalidated before using
+func (cache *CacheControl) NoCache() *CacheControl {
+	return cache.flag("no-cache", "")
+}
+
+//NoStore The response cannot be stored by any cache
+func (cache *CacheControl) NoStore() *CacheControl {
+	return cache.flag("no-store", "")
+}
+
+//NoTransform The response cannot be transformed by intermediaries
+func (cache *CacheControl) NoTransform() *CacheControl {
+	return cache.flag("no-transform", "")
+}
+
+//MustRevalidate The stale response must be revalidated
+func (cache *CacheControl) MustRevalidate() *CacheControl {
+	return cache.flag("must-revalidate", "")
+}
+
+//ProxyRevalidate The stale response must be revalidated by shared caches
+func (cache *CacheControl) ProxyRevalidate() *CacheControl {
+	return cache.flag("proxy-revalidate", "")
+}
+
+//Immutable The response will not be changed while it is fresh
+func (cache *CacheControl) Immutable() *CacheControl {
+	return cache.flag("immutable", "")
+}
+
+//MaxAge The response is fresh in duration
+func (cache *CacheControl) MaxAge(duration time.Duration) *CacheControl {
+	return cache.duration("max-age", duration)
+}
+
+//SMaxAge The response is fresh in duration for shared caches
+func (cache *CacheControl) SMaxAge(duration time.Duration) *CacheControl {
+	return cache.duration("s-maxage", duration)
+}
+
+//SWR The stale response can be used while revalidating in background (stale-while-revalidate)
+func (cache *CacheControl) SWR(duration time.Duration) *CacheControl {
+	return cache.duration("stale-while-revalidate", duration)
+}
+
+//StaleIfError The stale response can be used if revalidation failed
+func (cache *CacheControl) StaleIfError(duration time.Duration) *CacheControl {
+	return cache.duration("stale-if-error", duration)
+}
+
+//Reset Remove all directives (e.g. to override the route policy)
+func (cache *CacheControl) Reset() *CacheControl {
+	cache.flags, cache.directives, cache.durations = nil, nil, map[string]time.Duration{}
+	return cache
+}
+
+//Cacheable The response can be stored by shared caches
+func (cache *CacheControl) Cacheable() bool {
+	return !cache.has("private") && !cache.has("no-store") && !cache.has("no-cache") && cache.TTL() > 0
+}
+
+//TTL The freshness lifetime for shared caches (s-maxage or max-age)
+func (cache *CacheControl) TTL() time.Duration {
+	if duration, existed := cache.durations["s-maxage"]; existed {
+		return duration
+	}
+	return cache.durations["max-age"]
+}
+
+//String The value of Cache-Control header
+func (cache *CacheControl) String() string {
+	directives := append([]string{}, cache.flags...)
+	for _, name := range cache.directives {
+		directives = append(directives, name+"="+strconv.FormatInt(int64(cache.durations[name]/time.Second), 10))
+	}
+	return strings.Join(directives, ", ")
+}
+
+//Apply Set the Cache-Control header of response and record it as the cache policy of context
+func (cache *CacheControl) Apply() {
+	if cache.ctx == nil {
+		return
+	}
+	cache.ctx.cache, cache.ctx.cachePending = cache.clone(), false
+	if value := cache.String(); len(value) > 0 {
+		cache.ctx.w.Header().Set("Cache-Control", value)
+	} else {
+		cache.ctx.w.Header().Del("Cache-Control")
+	}
+}
+
+//applyPolicy apply the route policy which is not overridden while the header is written
+func (ctx *Context) applyPolicy(httpstatus int) {
+	if !ctx.cachePending {
+		return
+	}
+	ctx.cachePending = false
+	if len(ctx.w.Header().Get("Cache-Control")) > 0 {
+		//overridden by handler
+		return
+	}
+	if (httpstatus >= 200 && httpstatus < 300) || httpstatus == http.StatusNotModified {
+		if value := ctx.cache.String(); len(value) > 0 {
+			ctx.w.Header().Set("Cache-Control", value)
+		}
+		return
+	}
+	//the policy of route is for the successful responses only
+	ctx.cache = nil
+	ctx.w.Header().Set("Cache-Control", "no-store")
+}
+
+func (cache *CacheControl) flag(name string, exclusive string) *CacheControl {
+	if len(exclusive) > 0 && cache.has(exclusive) {
+		for index, flag := range cache.flags {
+			if flag == exclusive {
+				cache.flags = append(cache.flags[:index], cache.flags[index+1:]...)
+				break
+			}
+		}
+	}
+	if !cache.has(name) {
+		cache.flags = append(cache.flags, name)
+	}
+	return cache
+}
+
+func (cache *CacheControl) duration(name string, duration time.Duration) *CacheControl {
+	if duration < 0 {
+		duration = 0
+	}
+	if _, existed := cache.durations[name]; !existed {
+		cache.directives = append(cache.directives, name)
+	}
+	cache.durations[name] = duration
+	return cache
+}
+
+func (cache *CacheControl) has(name string) bool {
+	for _, flag := range cache.flags {
+		if flag == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (cache *CacheControl) clone() *CacheControl {
+	copied := &CacheControl{
+		ctx:        cache.ctx,
+		flags:      append([]string{}, cache.flags...),
+		durations:  map[string]time.Duration{},
+		directives: append([]string{}, cache.directives...),
+	}
+	for name, duration := range cache.durations {
+		copied.durations[name] = duration
+	}
+	return copied
+}
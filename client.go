@@ -0,0 +1,422 @@
+package webapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	//clientGenerator generate go client source from route table
+	clientGenerator struct {
+		imports map[string]string //path -> alias
+		aliases map[string]bool
+		methods map[string]bool
+		types   map[reflect.Type]string
+		decls   bytes.Buffer
+	}
+)
+
+//placeholderTypes the go types of path placeholders for the routes without bound types
+var placeholderTypes = map[string]reflect.Type{
+	"{digits}": reflect.TypeOf(int64(0)),
+	"{float}":  reflect.TypeOf(float64(0)),
+	"{bool}":   reflect.TypeOf(false),
+	"{string}": reflect.TypeOf(""),
+}
+
+//GenerateClient Generate go client package source with one method per action (tenant routes are excluded).
+//The client sends body as JSON and replies with status code above 399 are returned as *Error.
+func (host *Host) GenerateClient(w io.Writer, pkgname string) error {
+	generator := &clientGenerator{
+		imports: map[string]string{},
+		aliases: map[string]bool{},
+		methods: map[string]bool{},
+		types:   map[reflect.Type]string{},
+	}
+	var methods bytes.Buffer
+	for _, route := range host.Report() {
+		if len(route.Tenant) > 0 {
+			continue
+		}
+		generator.method(&methods, route)
+	}
+	var src bytes.Buffer
+	src.WriteString("// Code generated by webapi. DO NOT EDIT.\n\npackage " + pkgname + "\n\nimport (\n")
+	for _, path := range []string{"bytes", "context", "encoding/json", "fmt", "io/ioutil", "net/http", "net/url", "reflect", "strings"} {
+		src.WriteString(strconv.Quote(path) + "\n")
+	}
+	var paths []string
+	for path := range generator.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if alias := generator.imports[path]; alias != path[strings.LastIndex(path, "/")+1:] {
+			src.WriteString(alias + " ")
+		}
+		src.WriteString(strconv.Quote(path) + "\n")
+	}
+	src.WriteString(")\n")
+	src.WriteString(clientRuntime)
+	src.Write(generator.decls.Bytes())
+	src.Write(methods.Bytes())
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+//method write client method of route
+func (generator *clientGenerator) method(w io.Writer, route RouteInfo) {
+	name := generator.methodName(route)
+	if len(name) == 0 {
+		return
+	}
+	var args, pathExpr []string
+	var literal string
+	var index int
+	for _, segment := range strings.Split(route.Path, "/")[1:] {
+		typ, isPlaceholder := placeholderTypes[segment]
+		if !isPlaceholder {
+			literal += "/" + segment
+			continue
+		}
+		if index < len(route.Params) {
+			typ = route.Params[index]
+		}
+		index++
+		arg := "param" + strconv.Itoa(index)
+		args = append(args, arg+" "+generator.typeExpr(typ))
+		pathExpr = append(pathExpr, strconv.Quote(literal+"/"), "url.PathEscape(fmt.Sprint("+arg+"))")
+		literal = ""
+	}
+	if len(literal) > 0 || len(pathExpr) == 0 {
+		pathExpr = append(pathExpr, strconv.Quote(literal))
+	}
+	var query, body = "nil", "nil"
+	if route.Query != nil {
+		args, query = append(args, "query "+generator.typeExpr(route.Query)), "encodeQuery(query)"
+	}
+	if route.Body != nil {
+		args, body = append(args, "body "+generator.typeExpr(route.Body)), "body"
+	}
+	result, decode := "[]byte", "raw"
+	if typ := route.Return; typ != nil && typ.Kind() != reflect.Interface {
+		elem := typ
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if marshalableKinds[elem.Kind()] && !(elem.Kind() == reflect.Slice && elem.Elem().Kind() == reflect.Uint8) {
+			result, decode = generator.typeExpr(typ), "json"
+		} else {
+			result, decode = "string", "text"
+		}
+	}
+	fmt.Fprintf(w, "\n//%s [%s] %s\n", name, route.Method, route.Path)
+	fmt.Fprintf(w, "func (c *Client) %s(ctx context.Context%s) (out %s, err error) {\n", name, strings.Join(append([]string{""}, args...), ", "), result)
+	fmt.Fprintf(w, "\tvar raw []byte\n\tif raw, err = c.Do(ctx, %q, %s, %s, %s); err != nil {\n\t\treturn\n\t}\n", route.Method, strings.Join(pathExpr, " + "), query, body)
+	switch decode {
+	case "json":
+		fmt.Fprintf(w, "\tif len(raw) > 0 {\n\t\terr = json.Unmarshal(raw, &out)\n\t}\n\treturn\n}\n")
+	case "text":
+		fmt.Fprintf(w, "\treturn string(raw), nil\n}\n")
+	default:
+		fmt.Fprintf(w, "\treturn raw, nil\n}\n")
+	}
+}
+
+//methodName the unique exported name of client method
+func (generator *clientGenerator) methodName(route RouteInfo) string {
+	var name string
+	if len(route.Controller) > 0 {
+		name = exportedName(route.Controller) + route.Action
+	} else {
+		name = exportedName(strings.ToLower(route.Method))
+		for _, segment := range strings.Split(route.Path, "/") {
+			if _, isPlaceholder := placeholderTypes[segment]; !isPlaceholder {
+				name += exportedName(segment)
+			}
+		}
+	}
+	if key := route.Controller + "." + route.Action + "." + route.Method; len(route.Controller) > 0 {
+		if generator.methods[key] {
+			//alias path of the same action
+			return ""
+		}
+		generator.methods[key] = true
+	}
+	if generator.methods[name] {
+		//action for multiple methods or the same names
+		name += exportedName(strings.ToLower(route.Method))
+	}
+	for index := 2; generator.methods[name]; index++ {
+		name = strings.TrimRight(name, "0123456789") + strconv.Itoa(index)
+	}
+	generator.methods[name] = true
+	return name
+}
+
+//typeExpr the go expression of type, the structures cannot be imported will be declared in client package
+func (generator *clientGenerator) typeExpr(typ reflect.Type) string {
+	if len(typ.Name()) > 0 {
+		if len(typ.PkgPath()) == 0 {
+			return typ.Name()
+		}
+		if typ.PkgPath() != "main" && unicode.IsUpper([]rune(typ.Name())[0]) && !strings.Contains(typ.PkgPath(), "internal") {
+			return generator.importPackage(typ.PkgPath(), typ.String()[:strings.Index(typ.String(), ".")]) + "." + typ.Name()
+		}
+		if typ.Kind() == reflect.Struct {
+			return generator.declare(typ)
+		}
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return "*" + generator.typeExpr(typ.Elem())
+	case reflect.Slice:
+		return "[]" + generator.typeExpr(typ.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(typ.Len()) + "]" + generator.typeExpr(typ.Elem())
+	case reflect.Map:
+		return "map[" + generator.typeExpr(typ.Key()) + "]" + generator.typeExpr(typ.Elem())
+	case reflect.Struct:
+		return "struct {\n" + generator.fields(typ) + "}"
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return "interface{}"
+	}
+	//unexported basic types
+	return typ.Kind().String()
+}
+
+//declare declare the structure in client package
+func (generator *clientGenerator) declare(typ reflect.Type) string {
+	if name, existed := generator.types[typ]; existed {
+		return name
+	}
+	name := exportedName(typ.Name())
+	for index := 2; generator.methods["type "+name]; index++ {
+		name = exportedName(typ.Name()) + strconv.Itoa(index)
+	}
+	//register the name first for recursive structures
+	generator.methods["type "+name], generator.types[typ] = true, name
+	decl := "\n//" + name + " " + typ.String() + "\ntype " + name + " struct {\n" + generator.fields(typ) + "}\n"
+	generator.decls.WriteString(decl)
+	return name
+}
+
+//fields the field declarations of structure (unexported fields are excluded)
+func (generator *clientGenerator) fields(typ reflect.Type) string {
+	var fields string
+	for index := 0; index < typ.NumField(); index++ {
+		field := typ.Field(index)
+		if embedded := field.Type; field.Anonymous && len(strings.Split(field.Tag.Get("json"), ",")[0]) == 0 {
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if expr := generator.typeExpr(field.Type); len(field.PkgPath) == 0 && len(embedded.Name()) > 0 {
+					fields += expr + tagExpr(field.Tag) + "\n"
+				} else {
+					//embedded structure which cannot be imported will be flattened
+					fields += generator.fields(embedded)
+				}
+				continue
+			}
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		fields += field.Name + " " + generator.typeExpr(field.Type) + tagExpr(field.Tag) + "\n"
+	}
+	return fields
+}
+
+//tagExpr the go expression of structure tag
+func tagExpr(tag reflect.StructTag) string {
+	if len(tag) == 0 {
+		return ""
+	}
+	if strings.Contains(string(tag), "`") {
+		return " " + strconv.Quote(string(tag))
+	}
+	return " `" + string(tag) + "`"
+}
+
+//importPackage get the alias of imported package
+func (generator *clientGenerator) importPackage(path string, name string) string {
+	if alias, existed := generator.imports[path]; existed {
+		return alias
+	}
+	alias := name
+	for index := 2; generator.aliases[alias] || clientReserved[alias]; index++ {
+		alias = name + strconv.Itoa(index)
+	}
+	generator.aliases[alias] = true
+	generator.imports[path] = alias
+	return alias
+}
+
+//exportedName convert text to exported go identifier
+func exportedName(text string) string {
+	var name []rune
+	upper := true
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name = append(name, r)
+	}
+	return string(name)
+}
+
+var clientReserved = map[string]bool{
+	"bytes": true, "context": true, "json": true, "fmt": true, "ioutil": true, "http": true, "url": true, "reflect": true, "strings": true,
+}
+
+//clientRuntime the common part of generated client
+const clientRuntime = `
+//Client API client
+type Client struct {
+	//BaseURL The base URL of service
+	BaseURL string
+
+	//HTTPClient The client to send requests, default is http.DefaultClient
+	HTTPClient *http.Client
+
+	//Header The header will be sent with each request
+	Header http.Header
+}
+
+//Error The error replied by service
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+//NewClient Create client for service
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Header:  http.Header{},
+	}
+}
+
+func (err *Error) Error() string {
+	if message := strings.TrimSpace(string(err.Body)); len(message) > 0 {
+		return fmt.Sprintf("%d %s: %s", err.StatusCode, http.StatusText(err.StatusCode), message)
+	}
+	return fmt.Sprintf("%d %s", err.StatusCode, http.StatusText(err.StatusCode))
+}
+
+//Do Send request and return the reply body
+func (c *Client) Do(ctx context.Context, method string, path string, query url.Values, body interface{}) ([]byte, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	addr := c.BaseURL + path
+	if len(query) > 0 {
+		addr += "?" + query.Encode()
+	}
+	var req *http.Request
+	var err error
+	if reader != nil {
+		req, err = http.NewRequest(method, addr, reader)
+	} else {
+		req, err = http.NewRequest(method, addr, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode > 399 {
+		return nil, &Error{StatusCode: resp.StatusCode, Body: data}
+	}
+	return data, nil
+}
+
+//encodeQuery encode structure into query with the rules of service
+func encodeQuery(obj interface{}) url.Values {
+	values := url.Values{}
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if value.Kind() != reflect.Struct {
+		return values
+	}
+	for index := 0; index < value.NumField(); index++ {
+		field, typ := value.Field(index), value.Type().Field(index)
+		if len(typ.PkgPath) > 0 {
+			continue
+		}
+		if field.Kind() == reflect.Struct {
+			for key, value := range encodeQuery(field.Interface()) {
+				values[key] = value
+			}
+			continue
+		}
+		name := strings.Split(typ.Tag.Get("json"), ",")[0]
+		if len(name) == 0 {
+			name = typ.Name
+		}
+		if name == "-" {
+			continue
+		}
+		for field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				break
+			}
+			field = field.Elem()
+		}
+		switch field.Kind() {
+		case reflect.Ptr:
+			continue
+		case reflect.Slice, reflect.Array:
+			items := make([]string, field.Len())
+			for i := range items {
+				items[i] = fmt.Sprint(field.Index(i).Interface())
+			}
+			if len(items) > 0 {
+				values.Set(name, strings.Join(items, ","))
+			}
+		default:
+			values.Set(name, fmt.Sprint(field.Interface()))
+		}
+	}
+	return values
+}
+`
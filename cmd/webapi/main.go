@@ -0,0 +1,207 @@
+//Command webapi Scaffold projects and controllers following the conventions of webapi
+//
+//	webapi new [-dir path] <module>
+//	webapi controller [-dir controllers] [-pkg name] [-force] <Name>
+//	webapi dispatch [-dir controllers] [-o dispatch_gen.go]
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "new":
+		err = newProject(os.Args[2:])
+	case "controller":
+		err = newController(os.Args[2:])
+	case "dispatch":
+		err = generateDispatch(os.Args[2:])
+	case "help", "-h", "-help", "--help":
+		usage()
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "webapi: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage:
+  webapi new [-dir path] <module>                                   create project with main.go and controllers
+  webapi controller [-dir controllers] [-pkg name] [-force] <Name>  create controller, test and registration
+  webapi dispatch [-dir controllers] [-o dispatch_gen.go]             generate reflection-free dispatch (build with -tags webapi_dispatch)`)
+}
+
+//newProject create go.mod, main.go and the controllers package with Home controller
+func newProject(args []string) error {
+	flags := flag.NewFlagSet("new", flag.ExitOnError)
+	dir := flags.String("dir", "", "the directory of project (default is the last element of module)")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("the module path is required")
+	}
+	data := scaffold{Module: flags.Arg(0), Package: "controllers"}
+	if len(*dir) == 0 {
+		*dir = filepath.Base(data.Module)
+	}
+	if err := render(filepath.Join(*dir, "go.mod"), goModTemplate, data, false); err != nil {
+		return err
+	}
+	if err := render(filepath.Join(*dir, "main.go"), mainTemplate, data, false); err != nil {
+		return err
+	}
+	return scaffoldController(filepath.Join(*dir, "controllers"), data.Package, "Home", false)
+}
+
+//newController create the controller in package directory
+func newController(args []string) error {
+	flags := flag.NewFlagSet("controller", flag.ExitOnError)
+	dir := flags.String("dir", "controllers", "the directory of package")
+	pkg := flags.String("pkg", "", "the package name (default is the name of directory)")
+	force := flags.Bool("force", false, "overwrite the existing files")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("the controller name is required")
+	}
+	if len(*pkg) == 0 {
+		absolute, err := filepath.Abs(*dir)
+		if err != nil {
+			return err
+		}
+		*pkg = filepath.Base(absolute)
+	}
+	return scaffoldController(*dir, *pkg, flags.Arg(0), *force)
+}
+
+//generateDispatch run the generator program which registers the controllers via Register of package
+func generateDispatch(args []string) error {
+	flags := flag.NewFlagSet("dispatch", flag.ExitOnError)
+	dir := flags.String("dir", "controllers", "the directory of package which declares Register(*webapi.Host, ...webapi.Middleware) error")
+	output := flags.String("o", "dispatch_gen.go", "the file name of generated source")
+	flags.Parse(args)
+	list := exec.Command("go", "list", "-f", "{{.ImportPath}}", ".")
+	list.Dir, list.Stderr = *dir, os.Stderr
+	importPath, err := list.Output()
+	if err != nil {
+		return err
+	}
+	generator := filepath.Join(*dir, "_webapi_dispatch")
+	defer os.RemoveAll(generator)
+	source := &bytes.Buffer{}
+	if err := generatorTemplate.Execute(source, scaffold{Module: strings.TrimSpace(string(importPath))}); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(generator, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(generator, "main.go"), source.Bytes(), 0644); err != nil {
+		return err
+	}
+	file, err := filepath.Abs(filepath.Join(*dir, *output))
+	if err != nil {
+		return err
+	}
+	run := exec.Command("go", "run", "./_webapi_dispatch", file)
+	run.Dir, run.Stdout, run.Stderr = *dir, os.Stdout, os.Stderr
+	return run.Run()
+}
+
+func scaffoldController(dir string, pkg string, name string, force bool) error {
+	name = strings.TrimSuffix(name, "Controller")
+	if len(name) == 0 || !isIdentifier(name) {
+		return errors.New("the controller name " + name + " is not a valid identifier")
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	data := scaffold{Package: pkg, Name: string(runes), Route: strings.ToLower(name)}
+	file := filepath.Join(dir, data.Route+".go")
+	if err := render(file, controllerTemplate, data, force); err != nil {
+		return err
+	}
+	if err := render(filepath.Join(dir, data.Route+"_test.go"), testTemplate, data, force); err != nil {
+		return err
+	}
+	return wire(filepath.Join(dir, "routes.go"), data)
+}
+
+//wire insert the controller into the registration list of routes.go
+func wire(file string, data scaffold) error {
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		if err = render(file, routesTemplate, data, false); err != nil {
+			return err
+		}
+	}
+	source, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	entry := "&" + data.Name + "{},"
+	if bytes.Contains(source, []byte(entry)) {
+		return nil
+	}
+	index := bytes.Index(source, []byte(marker))
+	if index == -1 {
+		return errors.New("cannot find " + marker + " in " + file + ", register " + entry + " manually")
+	}
+	source = append(source[:index:index], append([]byte(entry+"\n"), source[index:]...)...)
+	return write(file, source, "updated")
+}
+
+//render execute the template into file (the existing file will not be overwritten if not forced)
+func render(file string, tpl *template.Template, data scaffold, force bool) error {
+	if _, err := os.Stat(file); err == nil && !force {
+		return errors.New(file + " is already existed")
+	}
+	buffer := &bytes.Buffer{}
+	if err := tpl.Execute(buffer, data); err != nil {
+		return err
+	}
+	return write(file, buffer.Bytes(), "created")
+}
+
+func write(file string, source []byte, verb string) error {
+	if strings.HasSuffix(file, ".go") {
+		formatted, err := format.Source(source)
+		if err != nil {
+			return err
+		}
+		source = formatted
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, source, 0644); err != nil {
+		return err
+	}
+	fmt.Println(verb + " " + file)
+	return nil
+}
+
+func isIdentifier(name string) bool {
+	for index, char := range name {
+		if !unicode.IsLetter(char) && char != '_' && (index == 0 || !unicode.IsDigit(char)) {
+			return false
+		}
+	}
+	return true
+}
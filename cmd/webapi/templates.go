@@ -0,0 +1,217 @@
+package main
+
+import (
+	"text/template"
+)
+
+type (
+	//scaffold the data of templates
+	scaffold struct {
+		Module  string
+		Package string
+		Name    string
+		Route   string
+	}
+)
+
+//marker the line of routes.go which the controllers will be inserted before
+const marker = "//webapi:controllers"
+
+var (
+	mainTemplate = template.Must(template.New("main").Parse(`package main
+
+import (
+	"log"
+
+	"github.com/go-webapi/webapi"
+
+	"{{.Module}}/controllers"
+)
+
+func main() {
+	host := webapi.NewHost(webapi.Config{})
+	host.SetErrorHandler(webapi.ProblemHandler)
+	if err := controllers.Register(host); err != nil {
+		log.Fatal(err)
+	}
+	log.Fatal(host.Run(":8080"))
+}
+`))
+
+	goModTemplate = template.Must(template.New("go.mod").Parse(`module {{.Module}}
+
+go 1.13
+`))
+
+	routesTemplate = template.Must(template.New("routes").Parse(`package {{.Package}}
+
+import (
+	"github.com/go-webapi/webapi"
+)
+
+//Register Register the controllers with host
+func Register(host *webapi.Host, middlewares ...webapi.Middleware) error {
+	for _, controller := range []webapi.Controller{
+		` + marker + `
+	} {
+		if err := host.Register("", controller, middlewares...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+`))
+
+	controllerTemplate = template.Must(template.New("controller").Parse(`package {{.Package}}
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-webapi/webapi"
+)
+
+type (
+	//{{.Name}} The controller of {{.Route}}
+	{{.Name}} struct {
+		webapi.Controller ` + "`" + `api:"{{.Route}}" semantics:""` + "`" + `
+	}
+
+	//{{.Name}}Query The query of listing
+	{{.Name}}Query struct {
+		Page int ` + "`" + `json:"page"` + "`" + `
+		Size int ` + "`" + `json:"size"` + "`" + `
+	}
+
+	//{{.Name}}Request The body of creation
+	{{.Name}}Request struct {
+		Name string ` + "`" + `json:"name"` + "`" + `
+	}
+
+	//{{.Name}}Response The response of {{.Route}}
+	{{.Name}}Response struct {
+		ID   int    ` + "`" + `json:"id"` + "`" + `
+		Name string ` + "`" + `json:"name"` + "`" + `
+	}
+)
+
+//Check Validate the query
+func (query *{{.Name}}Query) Check() error {
+	if query.Page < 0 || query.Size < 0 {
+		return errors.New("page and size cannot be negative")
+	}
+	if query.Size == 0 {
+		query.Size = 20
+	}
+	return nil
+}
+
+//Check Validate the body
+func (request *{{.Name}}Request) Check() error {
+	if len(request.Name) == 0 {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+//GetList [GET] /{{.Route}}/List
+func (controller *{{.Name}}) GetList(query {{.Name}}Query) []{{.Name}}Response {
+	return []{{.Name}}Response{}
+}
+
+//Get [GET] /{{.Route}}/{id}
+func (controller *{{.Name}}) Get(id int) (*{{.Name}}Response, error) {
+	if id <= 0 {
+		return nil, webapi.NewHTTPError(http.StatusNotFound)
+	}
+	return &{{.Name}}Response{ID: id}, nil
+}
+
+//Post [POST] /{{.Route}}
+func (controller *{{.Name}}) Post(request *{{.Name}}Request) *webapi.Reply {
+	return &webapi.Reply{
+		Status: http.StatusCreated,
+		Body:   &{{.Name}}Response{ID: 1, Name: request.Name},
+	}
+}
+`))
+
+	generatorTemplate = template.Must(template.New("generator").Parse(`package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/go-webapi/webapi"
+
+	target "{{.Module}}"
+)
+
+func main() {
+	host := webapi.NewHost(webapi.Config{DisableAutoReport: true})
+	if err := target.Register(host); err != nil {
+		fail(err)
+	}
+	buffer := &bytes.Buffer{}
+	if err := host.GenerateDispatch(buffer, "{{.Module}}"); err != nil {
+		fail(err)
+	}
+	if err := ioutil.WriteFile(os.Args[1], buffer.Bytes(), 0644); err != nil {
+		fail(err)
+	}
+	fmt.Println("created " + os.Args[1])
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+`))
+
+	testTemplate = template.Must(template.New("test").Parse(`package {{.Package}}
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-webapi/webapi"
+	"github.com/go-webapi/webapi/webapitest"
+)
+
+func new{{.Name}}Client(t *testing.T) *webapitest.Client {
+	host := webapi.NewHost(webapi.Config{DisableAutoReport: true})
+	if err := host.Register("", &{{.Name}}{}); err != nil {
+		t.Fatal(err)
+	}
+	return webapitest.NewClient(host)
+}
+
+func Test{{.Name}}Get(t *testing.T) {
+	client := new{{.Name}}Client(t)
+	var response {{.Name}}Response
+	client.Get("/{{.Route}}/1").Expect(t).Status(http.StatusOK).JSON(&response)
+	if response.ID != 1 {
+		t.Errorf("expected id 1 but got %d", response.ID)
+	}
+	client.Get("/{{.Route}}/0").Expect(t).Status(http.StatusNotFound)
+}
+
+func Test{{.Name}}Post(t *testing.T) {
+	client := new{{.Name}}Client(t)
+	var response {{.Name}}Response
+	client.Post("/{{.Route}}").WithJSON(&{{.Name}}Request{Name: "example"}).Expect(t).Status(http.StatusCreated).JSON(&response)
+	if response.Name != "example" {
+		t.Errorf("expected name example but got %q", response.Name)
+	}
+	client.Post("/{{.Route}}").WithJSON(&{{.Name}}Request{}).Expect(t).Status(http.StatusBadRequest)
+}
+
+func Test{{.Name}}List(t *testing.T) {
+	client := new{{.Name}}Client(t)
+	client.Get("/{{.Route}}/List").WithQuery("page", "-1").Expect(t).Status(http.StatusBadRequest)
+	client.Get("/{{.Route}}/List").Expect(t).Status(http.StatusOK).Body("[]")
+}
+`))
+)
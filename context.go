@@ -1,9 +1,13 @@
 package webapi
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"reflect"
 	"strconv"
@@ -30,13 +34,31 @@ type (
 
 	httpHandler func(*Context, ...string)
 
+	//ErrorHandler Handler for the errors which should be replied to client
+	ErrorHandler func(ctx *Context, httpstatus int, err error)
+
 	//Context HTTP Request Context
 	Context struct {
 		statuscode   int
 		w            http.ResponseWriter
 		r            *http.Request
+		host         *Host
+		tenant       string
 		body         []byte
+		bodyErr      error
 		predecessors []Middleware
+		cache        *CacheControl
+		cachePending bool //the route policy will be applied while the header is written
+		onFinish     []func(*Context)
+		retained     bool
+		arguments    []string //the buffer of path arguments sized by the routes of host
+		mediaType    MediaType
+		principal    interface{}
+		route        *RouteInfo
+		routeArgs    []string
+		audit        *AuditEvent
+		trailers     []trailer
+		expect       *continueBody
 
 		Deserializer Serializer
 		Serializer   Serializer
@@ -60,10 +82,23 @@ func (ctx *Context) Reply(httpstatus int, obj ...interface{}) (err error) {
 				//serializer is using for reply now.
 				//use deserializer to handle body data instead.
 				if ctx.Serializer == nil {
-					//default is json.
+					//default is json if host is not specified.
 					ctx.Serializer = Serializers["application/json"]
+					if ctx.host != nil {
+						if serializer := ctx.host.Serializer(""); serializer != nil {
+							ctx.Serializer = serializer
+						}
+					}
+				}
+				if stream, isStream := ctx.Serializer.(StreamSerializer); isStream {
+					//the buffer is released after written
+					buffer := getBuffer()
+					defer putBuffer(buffer)
+					err = stream.MarshalTo(buffer, value)
+					data = buffer.Bytes()
+				} else {
+					data, err = ctx.Serializer.Marshal(value)
 				}
-				data, err = ctx.Serializer.Marshal(value)
 				if len(ctx.w.Header().Get("Content-Type")) == 0 {
 					ctx.w.Header().Set("Content-Type", ctx.Serializer.ContentType())
 				}
@@ -87,6 +122,19 @@ func (ctx *Context) Reply(httpstatus int, obj ...interface{}) (err error) {
 	return ctx.Write(httpstatus, data)
 }
 
+//ReplyError Reply error to client via the error handler of host
+func (ctx *Context) ReplyError(httpstatus int, err error) {
+	if ctx.host != nil && ctx.host.onError != nil {
+		ctx.host.onError(ctx, httpstatus, err)
+		return
+	}
+	if err != nil {
+		ctx.Reply(httpstatus, err)
+	} else {
+		ctx.Reply(httpstatus)
+	}
+}
+
 //Write Write to response(only for once)
 func (ctx *Context) Write(httpstatus int, data []byte) (err error) {
 	if ctx.statuscode == 0 {
@@ -94,7 +142,7 @@ func (ctx *Context) Write(httpstatus int, data []byte) (err error) {
 		if ctx.BeforeWriting != nil && len(data) > 0 {
 			data = ctx.BeforeWriting(ctx.statuscode, data)
 		}
-		ctx.w.WriteHeader(httpstatus)
+		ctx.writeHeader(httpstatus)
 		if len(data) > 0 {
 			_, err = ctx.w.Write(data)
 		}
@@ -104,12 +152,29 @@ func (ctx *Context) Write(httpstatus int, data []byte) (err error) {
 	return
 }
 
+//writeHeader write the status code with the header prepared
+func (ctx *Context) writeHeader(httpstatus int) {
+	ctx.prepareHeader(httpstatus)
+	ctx.w.WriteHeader(httpstatus)
+}
+
+//prepareHeader apply the cache policy of route, declare the trailers, and close the connection if the client is still waiting for 100 Continue
+//(the body is not sent, reusing the connection would mix the late body with the next request)
+func (ctx *Context) prepareHeader(httpstatus int) {
+	ctx.applyPolicy(httpstatus)
+	ctx.declareTrailers()
+	if ctx.ExpectsContinue() && ctx.r.ProtoMajor == 1 {
+		ctx.w.Header().Set("Connection", "close")
+	}
+}
+
 //Redirect Jump to antoher url
 func (ctx *Context) Redirect(addr string, httpstatus ...int) {
 	if len(httpstatus) == 0 || !(httpstatus[0] > 299 && httpstatus[0] < 400) {
 		httpstatus = []int{http.StatusTemporaryRedirect}
 	}
 	ctx.statuscode = httpstatus[0]
+	ctx.prepareHeader(httpstatus[0])
 	http.Redirect(ctx.w, ctx.r, addr, httpstatus[0])
 }
 
@@ -142,17 +207,77 @@ func (ctx *Context) GetResponseWriter() ResponseWriter {
 	}
 }
 
-//Body The Body Bytes from Context
+//Body The Body Bytes from Context, the body will be read and buffered at the first call.
+//The raw bytes are kept as they are (BeforeReading is applied while binding only),
+//and the request body will be replaced with the buffered data to be read again (e.g. ParseForm).
+//It is empty if the body has been streamed into the body parameter by StreamDeserializer.
 func (ctx *Context) Body() []byte {
 	if ctx.r.Body != nil && ctx.body == nil {
-		ctx.body, _ = ioutil.ReadAll(ctx.r.Body)
+		ctx.body, ctx.bodyErr = ioutil.ReadAll(ctx.r.Body)
 		if ctx.body == nil {
 			ctx.body = []byte{}
 		}
+		ctx.r.Body.Close()
+		ctx.r.Body = ioutil.NopCloser(bytes.NewReader(ctx.body))
 	}
 	return ctx.body
 }
 
+//BodyReader The Body Reader from Context to support stream read,
+//the buffered data will be returned if the body has been read by Body()
+func (ctx *Context) BodyReader() io.Reader {
+	if ctx.body != nil {
+		return bytes.NewReader(ctx.body)
+	}
+	if ctx.r.Body == nil {
+		return http.NoBody
+	}
+	return ctx.r.Body
+}
+
+//BodyError The error occurred while reading body by Body()
+func (ctx *Context) BodyError() error {
+	return ctx.bodyErr
+}
+
+//Hijack Take over the connection (e.g. websocket), the context cannot be used to reply after hijacked
+func (ctx *Context) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, isHijacker := ctx.w.(http.Hijacker)
+	if !isHijacker {
+		return nil, nil, errors.New("the response writer does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		//mark the connection has been taken over
+		ctx.statuscode = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+//OnFinish Register the callback which will be invoked after the response has been handled (e.g. metrics, cleaning up),
+//the context is reused by the later requests after the callbacks, so it must not be used outside of the request
+//unless Retain() is called
+func (ctx *Context) OnFinish(callback func(*Context)) {
+	ctx.onFinish = append(ctx.onFinish, callback)
+}
+
+//Retain Keep the context from being reused after the request (e.g. used by goroutines after the handler returned)
+func (ctx *Context) Retain() {
+	ctx.retained = true
+}
+
+//reset clear the context for reusing, the buffers will not be reused because they might be retained by handlers
+func (ctx *Context) reset() {
+	for index := range ctx.onFinish {
+		ctx.onFinish[index] = nil
+	}
+	arguments := ctx.arguments[:cap(ctx.arguments)]
+	for index := range arguments {
+		arguments[index] = ""
+	}
+	*ctx = Context{onFinish: ctx.onFinish[:0], arguments: arguments[:0]}
+}
+
 //StatusCode Context Status Code
 func (ctx *Context) StatusCode() int {
 	return ctx.statuscode
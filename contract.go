@@ -0,0 +1,294 @@
+package webapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	//ContractMissing The endpoint is declared by spec but not registered
+	ContractMissing = "missing"
+	//ContractUndocumented The endpoint is registered but not declared by spec
+	ContractUndocumented = "undocumented"
+	//ContractMismatch The parameters or schemas are different from spec
+	ContractMismatch = "mismatch"
+)
+
+type (
+	//ContractIssue The difference between the routes and the OpenAPI spec
+	ContractIssue struct {
+		Kind   string
+		Method string
+		//Path The path of spec (or the path generated from route if undocumented)
+		Path string
+		//Location Where the mismatch is, e.g. query.page, requestBody.items[].name, responses.200.id
+		Location string `json:",omitempty"`
+		Message  string
+	}
+
+	//contractChecker compare operations with the components of both documents
+	contractChecker struct {
+		spec   *OpenAPIDocument
+		live   *OpenAPIDocument
+		issues []ContractIssue
+	}
+)
+
+//LoadOpenAPI Read OpenAPI 3 document (JSON), the path level parameters are merged into operations
+func LoadOpenAPI(r io.Reader) (*OpenAPIDocument, error) {
+	var raw struct {
+		OpenAPI    string                                `json:"openapi"`
+		Info       OpenAPIInfo                           `json:"info"`
+		Servers    []OpenAPIServer                       `json:"servers"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components OpenAPIComponents                     `json:"components"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	doc := &OpenAPIDocument{
+		OpenAPI:    raw.OpenAPI,
+		Info:       raw.Info,
+		Servers:    raw.Servers,
+		Paths:      map[string]map[string]*OpenAPIOperation{},
+		Components: raw.Components,
+	}
+	for path, items := range raw.Paths {
+		var shared []*OpenAPIParameter
+		if data, existed := items["parameters"]; existed {
+			if err := json.Unmarshal(data, &shared); err != nil {
+				return nil, err
+			}
+		}
+		operations := map[string]*OpenAPIOperation{}
+		for method, data := range items {
+			if !supportedMthods[strings.ToUpper(method)] {
+				continue
+			}
+			operation := &OpenAPIOperation{}
+			if err := json.Unmarshal(data, operation); err != nil {
+				return nil, err
+			}
+			for _, param := range shared {
+				if findParameter(operation.Parameters, param.In, param.Name) == nil {
+					operation.Parameters = append(operation.Parameters, param)
+				}
+			}
+			operations[strings.ToLower(method)] = operation
+		}
+		doc.Paths[path] = operations
+	}
+	return doc, nil
+}
+
+//CheckContract Compare the routes (tenant routes are excluded) and their reflected schemas with the OpenAPI spec,
+//the endpoints are matched by method and path (the names of path parameters are ignored)
+func (host *Host) CheckContract(spec *OpenAPIDocument) []ContractIssue {
+	checker := &contractChecker{spec: spec, live: host.OpenAPI(spec.Info)}
+	routes := map[string]string{}
+	for path := range checker.live.Paths {
+		routes[contractKey(path, host.conf.UseLowerLetter)] = path
+	}
+	documented := map[string]bool{}
+	for _, path := range sortedKeys(spec.Paths) {
+		key := contractKey(path, host.conf.UseLowerLetter)
+		for _, method := range sortedKeys(spec.Paths[path]) {
+			documented[method+" "+key] = true
+			var actual *OpenAPIOperation
+			if livePath, existed := routes[key]; existed {
+				actual = checker.live.Paths[livePath][method]
+			}
+			if actual == nil {
+				checker.report(ContractMissing, method, path, "", "the endpoint is not registered")
+				continue
+			}
+			checker.operation(method, path, spec.Paths[path][method], actual)
+		}
+	}
+	for _, path := range sortedKeys(checker.live.Paths) {
+		key := contractKey(path, host.conf.UseLowerLetter)
+		for _, method := range sortedKeys(checker.live.Paths[path]) {
+			if !documented[method+" "+key] {
+				checker.report(ContractUndocumented, method, path, "", "the endpoint is not declared by spec")
+			}
+		}
+	}
+	return checker.issues
+}
+
+//ServeContract Register the endpoint which replies the contract issues against spec (e.g. with the auth middleware)
+func (host *Host) ServeContract(path string, spec *OpenAPIDocument, middlewares ...Middleware) error {
+	if len(path) == 0 {
+		path = "/contract"
+	}
+	return host.AddEndpoint(http.MethodGet, path, func(ctx *Context) {
+		replyJSON(ctx, http.StatusOK, host.CheckContract(spec))
+	}, middlewares...)
+}
+
+func (checker *contractChecker) report(kind, method, path, location, message string) {
+	checker.issues = append(checker.issues, ContractIssue{
+		Kind:     kind,
+		Method:   strings.ToUpper(method),
+		Path:     path,
+		Location: location,
+		Message:  message,
+	})
+}
+
+//operation compare parameters, request body and responses
+func (checker *contractChecker) operation(method, path string, expected, actual *OpenAPIOperation) {
+	mismatch := func(location, message string) {
+		checker.report(ContractMismatch, method, path, location, message)
+	}
+	var expectedPath, actualPath []*OpenAPIParameter
+	for _, param := range expected.Parameters {
+		if param.In == "path" {
+			expectedPath = append(expectedPath, param)
+		}
+	}
+	sort.SliceStable(expectedPath, func(i, j int) bool {
+		return strings.Index(path, "{"+expectedPath[i].Name+"}") < strings.Index(path, "{"+expectedPath[j].Name+"}")
+	})
+	for _, param := range actual.Parameters {
+		if param.In == "path" {
+			actualPath = append(actualPath, param)
+		}
+	}
+	for index := 0; index < len(expectedPath) && index < len(actualPath); index++ {
+		checker.schema("path."+expectedPath[index].Name, expectedPath[index].Schema, actualPath[index].Schema, mismatch, 0)
+	}
+	for _, param := range expected.Parameters {
+		if param.In != "query" {
+			continue
+		}
+		if found := findParameter(actual.Parameters, "query", param.Name); found == nil {
+			mismatch("query."+param.Name, "the query parameter is not bound")
+		} else {
+			checker.schema("query."+param.Name, param.Schema, found.Schema, mismatch, 0)
+		}
+	}
+	for _, param := range actual.Parameters {
+		if param.In == "query" && findParameter(expected.Parameters, "query", param.Name) == nil {
+			mismatch("query."+param.Name, "the query parameter is not declared by spec")
+		}
+	}
+	switch expectedBody, actualBody := contentSchema(expected.RequestBody), contentSchema(actual.RequestBody); {
+	case expectedBody != nil && actualBody == nil:
+		mismatch("requestBody", "the request body is not bound")
+	case expectedBody == nil && actualBody != nil:
+		mismatch("requestBody", "the request body is not declared by spec")
+	case expectedBody != nil:
+		checker.schema("requestBody", expectedBody, actualBody, mismatch, 0)
+	}
+	for _, code := range sortedKeys(expected.Responses) {
+		if response := actual.Responses[code]; response != nil && len(response.Content) > 0 && len(expected.Responses[code].Content) > 0 {
+			checker.schema("responses."+code, mediaSchema(expected.Responses[code].Content), mediaSchema(response.Content), mismatch, 0)
+		}
+	}
+}
+
+//schema compare the types and properties recursively
+func (checker *contractChecker) schema(location string, expected, actual *OpenAPISchema, mismatch func(string, string), depth int) {
+	expected, actual = resolveSchema(checker.spec, expected), resolveSchema(checker.live, actual)
+	if expected == nil || actual == nil || depth > 8 {
+		return
+	}
+	if len(expected.Type) > 0 && len(actual.Type) > 0 && expected.Type != actual.Type {
+		mismatch(location, "the type is "+actual.Type+" but "+expected.Type+" is declared")
+		return
+	}
+	switch expected.Type {
+	case "array":
+		checker.schema(location+"[]", expected.Items, actual.Items, mismatch, depth+1)
+	case "object":
+		for _, name := range sortedKeys(expected.Properties) {
+			if property, existed := actual.Properties[name]; !existed {
+				if actual.Properties != nil {
+					mismatch(location+"."+name, "the property is not declared by structure")
+				}
+			} else {
+				checker.schema(location+"."+name, expected.Properties[name], property, mismatch, depth+1)
+			}
+		}
+		if expected.Properties != nil {
+			for _, name := range sortedKeys(actual.Properties) {
+				if _, existed := expected.Properties[name]; !existed {
+					mismatch(location+"."+name, "the property is not declared by spec")
+				}
+			}
+		}
+		checker.schema(location+"{}", expected.AdditionalProperties, actual.AdditionalProperties, mismatch, depth+1)
+	}
+}
+
+//resolveSchema follow the references and single allOf
+func resolveSchema(doc *OpenAPIDocument, schema *OpenAPISchema) *OpenAPISchema {
+	for depth := 0; schema != nil && depth < 8; depth++ {
+		if len(schema.Ref) > 0 {
+			schema = doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		} else if len(schema.AllOf) == 1 && len(schema.Type) == 0 {
+			schema = schema.AllOf[0]
+		} else {
+			break
+		}
+	}
+	return schema
+}
+
+func contentSchema(body *OpenAPIRequestBody) *OpenAPISchema {
+	if body == nil {
+		return nil
+	}
+	return mediaSchema(body.Content)
+}
+
+//mediaSchema the schema of JSON content (or the first content)
+func mediaSchema(content map[string]OpenAPIMediaType) *OpenAPISchema {
+	keys := sortedKeys(content)
+	for _, key := range keys {
+		if strings.Contains(key, "json") {
+			return content[key].Schema
+		}
+	}
+	if len(keys) > 0 {
+		return content[keys[0]].Schema
+	}
+	return nil
+}
+
+func findParameter(params []*OpenAPIParameter, in string, name string) *OpenAPIParameter {
+	for _, param := range params {
+		if param.In == in && param.Name == name {
+			return param
+		}
+	}
+	return nil
+}
+
+//contractKey the path without the names of parameters
+func contractKey(path string, lower bool) string {
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[index] = "{}"
+		} else if lower {
+			segments[index] = strings.ToLower(segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+//sortedKeys the sorted keys of map
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,130 @@
+package webapi
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+)
+
+var (
+	//ErrNoCryptoService The crypto service is not set by host.SetCryptoService
+	ErrNoCryptoService = errors.New("the crypto service is not set")
+
+	//ErrMalformedCiphertext The ciphertext cannot be decrypted (it is forged, truncated or encrypted by unknown key)
+	ErrMalformedCiphertext = errors.New("malformed ciphertext")
+)
+
+type (
+	//CryptoService The service which encrypts the values stored by clients (e.g. encrypted cookies),
+	//the additional data is authenticated but not encrypted
+	CryptoService interface {
+		Encrypt(plaintext []byte, additional []byte) ([]byte, error)
+		Decrypt(ciphertext []byte, additional []byte) ([]byte, error)
+	}
+
+	//aesGCM the AES-GCM crypto service, the ciphertext is prefixed with the key ID and the nonce
+	aesGCM struct {
+		current string
+		keys    map[string]cipher.AEAD
+	}
+)
+
+//NewAESGCM Create the AES-GCM crypto service with the keys (16, 24 or 32 bytes) by key ID,
+//the values are encrypted by the current key and decrypted by the key they were encrypted with,
+//so the previous keys should be kept until their values are expired when the keys are rotated
+func NewAESGCM(currentKeyID string, keys map[string][]byte) (CryptoService, error) {
+	service := &aesGCM{current: currentKeyID, keys: map[string]cipher.AEAD{}}
+	for id, key := range keys {
+		if len(id) > 255 {
+			return nil, errors.New("the key ID " + id + " is longer than 255 bytes")
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if service.keys[id], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	if _, existed := service.keys[currentKeyID]; !existed {
+		return nil, errors.New("the current key " + currentKeyID + " is not provided")
+	}
+	return service, nil
+}
+
+func (service *aesGCM) Encrypt(plaintext []byte, additional []byte) ([]byte, error) {
+	aead := service.keys[service.current]
+	prefix := len(service.current) + 1
+	data := make([]byte, prefix+aead.NonceSize(), prefix+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	data[0] = byte(len(service.current))
+	copy(data[1:], service.current)
+	nonce := data[prefix:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(data, nonce, plaintext, additional), nil
+}
+
+func (service *aesGCM) Decrypt(ciphertext []byte, additional []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || len(ciphertext) < int(ciphertext[0])+1 {
+		return nil, ErrMalformedCiphertext
+	}
+	prefix := int(ciphertext[0]) + 1
+	aead, existed := service.keys[string(ciphertext[1:prefix])]
+	if !existed || len(ciphertext) < prefix+aead.NonceSize()+aead.Overhead() {
+		return nil, ErrMalformedCiphertext
+	}
+	nonce := ciphertext[prefix : prefix+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, ciphertext[prefix+aead.NonceSize():], additional)
+	if err != nil {
+		return nil, ErrMalformedCiphertext
+	}
+	return plaintext, nil
+}
+
+//SetCryptoService Set the crypto service used by encrypted cookies
+func (host *Host) SetCryptoService(service CryptoService) *Host {
+	host.crypto = service
+	return host
+}
+
+//SetEncryptedCookie Set the cookie whose value is encrypted by the crypto service of host,
+//the value is bound to the cookie name and cannot be moved to another cookie
+func (ctx *Context) SetEncryptedCookie(cookie *http.Cookie) error {
+	if ctx.host == nil || ctx.host.crypto == nil {
+		return ErrNoCryptoService
+	}
+	data, err := ctx.host.crypto.Encrypt([]byte(cookie.Value), []byte(cookie.Name))
+	if err != nil {
+		return err
+	}
+	encrypted := *cookie
+	encrypted.Value = base64.RawURLEncoding.EncodeToString(data)
+	ctx.SetCookies(&encrypted)
+	return nil
+}
+
+//GetEncryptedCookie Get the decrypted value of cookie set by SetEncryptedCookie,
+//http.ErrNoCookie is returned if the cookie is not found
+func (ctx *Context) GetEncryptedCookie(name string) (string, error) {
+	if ctx.host == nil || ctx.host.crypto == nil {
+		return "", ErrNoCryptoService
+	}
+	cookie, err := ctx.r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return "", ErrMalformedCiphertext
+	}
+	value, err := ctx.host.crypto.Decrypt(data, []byte(name))
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
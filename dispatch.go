@@ -0,0 +1,90 @@
+package webapi
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+)
+
+//DispatchTag The build tag of the files generated by GenerateDispatch
+const DispatchTag = "webapi_dispatch"
+
+type (
+	//Dispatch The generated function which binds the arguments and invokes the action without reflection,
+	//the results are replied in the same way as reflection (args are the path parameters including Init)
+	Dispatch func(ctx *Context, args []string) ([]interface{}, error)
+
+	dispatchKey struct {
+		controller reflect.Type
+		action     string
+	}
+)
+
+var (
+	dispatchMutex sync.RWMutex
+	dispatches    = map[dispatchKey]Dispatch{}
+)
+
+//RegisterDispatch Register the generated dispatch of action (it is called by the init function of generated file),
+//the actions of the controllers registered with host later will be invoked by dispatch instead of reflection
+func RegisterDispatch(controller Controller, action string, dispatch Dispatch) {
+	dispatchMutex.Lock()
+	defer dispatchMutex.Unlock()
+	dispatches[dispatchKey{reflect.TypeOf(controller), action}] = dispatch
+}
+
+//dispatchOf the registered dispatch of action, nil if the reflection is required
+func dispatchOf(controller reflect.Type, action string) Dispatch {
+	dispatchMutex.RLock()
+	defer dispatchMutex.RUnlock()
+	return dispatches[dispatchKey{controller, action}]
+}
+
+//BindBody Deserialize the body into target in the same way as the body parameter of action
+//(the body will not be read if the deserializer is not matched)
+func (ctx *Context) BindBody(target interface{}) error {
+	if stream, reader := ctx.bodyStream(); stream != nil {
+		if reader == nil {
+			return nil
+		}
+		return ctx.streamError(stream.UnmarshalStream(reader, target))
+	}
+	if ctx.Deserializer == nil {
+		return nil
+	}
+	body, err := ctx.bindingBody()
+	if err != nil || len(body) == 0 {
+		return err
+	}
+	return ctx.Deserializer.Unmarshal(body, target)
+}
+
+//bindingBody the body to bind (BeforeReading is applied)
+func (ctx *Context) bindingBody() ([]byte, error) {
+	var body = ctx.Body()
+	if ctx.bodyErr != nil {
+		if errors.Is(ctx.bodyErr, ErrBodyTooLarge) {
+			return nil, NewHTTPError(http.StatusRequestEntityTooLarge, ctx.bodyErr)
+		}
+		return nil, ctx.bodyErr
+	}
+	if ctx.BeforeReading != nil {
+		body = ctx.BeforeReading(body)
+	}
+	return body, nil
+}
+
+//QueryValue The first value of the first name existed in queries (the lookup rule of query fields)
+func QueryValue(queries url.Values, names ...string) (string, bool) {
+	for _, name := range names {
+		if values, existed := queries[name]; existed {
+			if len(values) == 0 {
+				return "", true
+			}
+			return values[0], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,405 @@
+package webapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	//dispatchGenerator the imports and variables of generated source
+	dispatchGenerator struct {
+		pkgPath string
+		imports map[string]string
+		aliases map[string]bool
+		counter int
+	}
+)
+
+//GenerateDispatch Generate the reflection-free dispatch of the actions of controllers (registered with host) declared in package pkgPath,
+//the source belongs to pkgPath and is guarded by DispatchTag, the actions which cannot be generated are left to reflection
+func (host *Host) GenerateDispatch(w io.Writer, pkgPath string) error {
+	self := reflect.TypeOf(Host{}).PkgPath()
+	g := &dispatchGenerator{
+		pkgPath: pkgPath,
+		imports: map[string]string{self: "webapi"},
+		aliases: map[string]bool{"webapi": true},
+	}
+	var pkgName string
+	var funcs []string
+	generated := map[reflect.Type]bool{}
+	for _, controller := range host.controllers {
+		typ := reflect.TypeOf(controller)
+		if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct || typ.Elem().PkgPath() != pkgPath || generated[typ] {
+			continue
+		}
+		generated[typ] = true
+		pkgName = strings.SplitN(typ.Elem().String(), ".", 2)[0]
+		funcs = append(funcs, host.generateController(g, typ, controller)...)
+	}
+	if len(pkgName) == 0 {
+		return errors.New("no controller of " + pkgPath + " is registered")
+	}
+	buffer := &bytes.Buffer{}
+	buffer.WriteString("// Code generated by webapi GenerateDispatch. DO NOT EDIT.\n\n")
+	buffer.WriteString("//go:build " + DispatchTag + "\n// +build " + DispatchTag + "\n\n")
+	buffer.WriteString("package " + pkgName + "\n\nimport (\n")
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		//the standard packages are placed at first
+		if isStandard(paths[i]) != isStandard(paths[j]) {
+			return isStandard(paths[i])
+		}
+		return paths[i] < paths[j]
+	})
+	for index, path := range paths {
+		if index > 0 && isStandard(paths[index-1]) && !isStandard(path) {
+			buffer.WriteString("\n")
+		}
+		if alias := g.imports[path]; alias == path[strings.LastIndex(path, "/")+1:] {
+			buffer.WriteString(strconv.Quote(path) + "\n")
+		} else {
+			buffer.WriteString(alias + " " + strconv.Quote(path) + "\n")
+		}
+	}
+	buffer.WriteString(")\n\nfunc init() {\n")
+	for _, f := range funcs {
+		buffer.WriteString(f)
+	}
+	buffer.WriteString("}\n")
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(source)
+	return err
+}
+
+//generateController generate the registrations of the actions in the same order as Register
+func (host *Host) generateController(g *dispatchGenerator, typ reflect.Type, controller Controller) (funcs []string) {
+	contextArgs, _, err := getControllerArguments(controller)
+	if err != nil {
+		return
+	}
+	_, semantics := host.getBasePath(controller)
+	for index := 0; index < typ.NumMethod(); index++ {
+		method := typ.Method(index)
+		if internalControllerMethods[method.Name] || (method.Name == "Init" && contextArgs != nil) || method.Type.IsVariadic() {
+			continue
+		}
+		ep, _, _, err := host.getMethodArguments(method, contextArgs, semantics)
+		if err != nil {
+			continue
+		}
+		imports, aliases := g.snapshot()
+		if lines, ok := g.action(typ, method, ep); !ok {
+			//the imports of partial statements are discarded
+			g.imports, g.aliases = imports, aliases
+		} else {
+			funcs = append(funcs, "webapi.RegisterDispatch((*"+typ.Elem().Name()+")(nil), "+strconv.Quote(method.Name)+
+				", func(ctx *webapi.Context, args []string) ([]interface{}, error) {\n"+strings.Join(lines, "\n")+"\n})\n")
+		}
+	}
+	return
+}
+
+//action the statements which create controller, bind arguments and invoke the method
+func (g *dispatchGenerator) action(typ reflect.Type, method reflect.Method, ep *function) (lines []string, ok bool) {
+	g.counter = 0
+	setup, found, ok := g.setController("c", typ.Elem())
+	if !ok || !found {
+		return nil, false
+	}
+	lines = append([]string{"c := &" + typ.Elem().Name() + "{}"}, setup...)
+	if ep.ContextArgs != nil {
+		var names []string
+		for index, arg := range ep.ContextArgs {
+			name := g.variable("a")
+			binding, ok := g.declare(name, arg, "args["+strconv.Itoa(index)+"]")
+			if !ok {
+				return nil, false
+			}
+			lines = append(lines, binding...)
+			names = append(names, name)
+		}
+		lines = append(lines, "if err := c.Init("+strings.Join(names, ", ")+"); err != nil {", "return nil, err", "}")
+	}
+	var names []string
+	var index = len(ep.ContextArgs)
+	for _, arg := range ep.Args {
+		name := g.variable("a")
+		var binding []string
+		switch {
+		case arg.isBody:
+			binding, ok = g.body(name, arg.Type)
+		case arg.isQuery:
+			binding, ok = g.query(name, arg.Type)
+		default:
+			binding, ok = g.declare(name, arg.Type, "args["+strconv.Itoa(index)+"]")
+			index++
+		}
+		if !ok {
+			return nil, false
+		}
+		lines = append(lines, binding...)
+		if arg.checker != -1 {
+			lines = append(lines, "if err := "+name+".Check(); err != nil {", "return nil, err", "}")
+		}
+		names = append(names, name)
+	}
+	call := "c." + method.Name + "(" + strings.Join(names, ", ") + ")"
+	if method.Type.NumOut() == 0 {
+		return append(lines, call, "return []interface{}{}, nil"), true
+	}
+	var results []string
+	for index := 0; index < method.Type.NumOut(); index++ {
+		results = append(results, "r"+strconv.Itoa(index))
+	}
+	return append(lines, strings.Join(results, ", ")+" := "+call, "return []interface{}{"+strings.Join(results, ", ")+"}, nil"), true
+}
+
+//setController assign the context to the embedded controller in the same way as setController
+func (g *dispatchGenerator) setController(target string, typ reflect.Type) (lines []string, found bool, ok bool) {
+	for index := 0; index < typ.NumField(); index++ {
+		field := typ.Field(index)
+		if name := field.Name; len(name) > 0 && strings.ToLower(name[:1]) == name[:1] {
+			continue
+		}
+		if field.Type.Kind() == reflect.Interface && field.Type.AssignableTo(types.Controller) {
+			return []string{target + "." + field.Name + " = ctx"}, true, field.Type == types.Controller
+		} else if field.Type.Kind() == reflect.Ptr {
+			elem := field.Type.Elem()
+			if elem.Kind() != reflect.Struct {
+				return nil, false, false
+			}
+			name := g.variable("f")
+			sublines, subfound, subok := g.setController(name, elem)
+			if !subok {
+				return nil, false, false
+			}
+			if subfound {
+				expr, ok := g.typeExpr(elem)
+				if !ok {
+					return nil, false, false
+				}
+				lines = append([]string{name + " := &" + expr + "{}"}, sublines...)
+				return append(lines, target+"."+field.Name+" = "+name), true, true
+			}
+		}
+	}
+	return nil, false, true
+}
+
+//body the statements which deserialize body into variable in the same way as loadFromBytes
+func (g *dispatchGenerator) body(name string, typ reflect.Type) ([]string, bool) {
+	if typ.Kind() == reflect.Ptr {
+		if typ.Elem().Kind() == reflect.Ptr {
+			return nil, false
+		}
+		expr, ok := g.typeExpr(typ.Elem())
+		return []string{name + " := new(" + expr + ")", "if err := ctx.BindBody(" + name + "); err != nil {", "return nil, err", "}"}, ok
+	}
+	expr, ok := g.typeExpr(typ)
+	return []string{"var " + name + " " + expr, "if err := ctx.BindBody(&" + name + "); err != nil {", "return nil, err", "}"}, ok
+}
+
+//query the statements which assign the queries to the fields of structure by binding plan
+func (g *dispatchGenerator) query(name string, typ reflect.Type) ([]string, bool) {
+	expr, ok := g.typeExpr(typ)
+	if !ok {
+		return nil, false
+	}
+	queries := g.variable("queries")
+	lines := []string{"var " + name + " " + expr, "if " + queries + " := ctx.GetRequest().URL.Query(); len(" + queries + ") > 0 {"}
+	for _, field := range planOf(typ).fields {
+		selector, owner := name, typ
+		for _, index := range field.index {
+			structField := owner.Field(index)
+			if len(structField.PkgPath) > 0 && owner.PkgPath() != g.pkgPath {
+				return nil, false
+			}
+			selector, owner = selector+"."+structField.Name, structField.Type
+		}
+		quoted := make([]string, len(field.names))
+		for index, name := range field.names {
+			quoted[index] = strconv.Quote(name)
+		}
+		value := g.variable("v")
+		assignment, ok := g.assign(selector, owner, value)
+		if !ok {
+			return nil, false
+		}
+		if len(assignment) == 0 {
+			continue
+		}
+		lines = append(lines, "if "+value+", existed := webapi.QueryValue("+queries+", "+strings.Join(quoted, ", ")+"); existed {")
+		lines = append(append(lines, assignment...), "}")
+	}
+	return append(lines, "}"), true
+}
+
+//declare the statements which declare the variable converted from source
+func (g *dispatchGenerator) declare(name string, typ reflect.Type, source string) ([]string, bool) {
+	expr, ok := g.typeExpr(typ)
+	if !ok {
+		return nil, false
+	}
+	assignment, ok := g.assign(name, typ, source)
+	return append([]string{"var " + name + " " + expr}, assignment...), ok
+}
+
+//assign the statements which convert source to target in the same way as converterOf
+func (g *dispatchGenerator) assign(target string, typ reflect.Type, source string) ([]string, bool) {
+	var parser string
+	switch typ.Kind() {
+	case reflect.String:
+		return []string{target + " = " + g.convert(typ, "string", source)}, true
+	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
+		parser = "strconv.ParseInt(" + source + ", 10, 64)"
+	case reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uint8, reflect.Uint16:
+		parser = "strconv.ParseUint(" + source + ", 10, 64)"
+	case reflect.Float32, reflect.Float64:
+		parser = "strconv.ParseFloat(" + source + ", 64)"
+	case reflect.Bool:
+		g.use("strings")
+		return []string{target + " = " + g.convert(typ, "bool", "strings.ToLower("+source+") == \"true\"")}, true
+	case reflect.Array:
+		array, index := g.variable("array"), g.variable("i")
+		elem, ok := g.assign(g.index(target, index), typ.Elem(), array+"["+index+"]")
+		if !ok || len(elem) == 0 {
+			return nil, ok
+		}
+		g.use("strings")
+		lines := []string{"{", array + " := strings.Split(" + source + ", \",\")",
+			"for " + index + " := 0; " + index + " < len(" + target + ") && " + index + " < len(" + array + "); " + index + "++ {"}
+		return append(append(lines, elem...), "}", "}"), true
+	case reflect.Slice:
+		expr, ok := g.typeExpr(typ)
+		if !ok {
+			return nil, false
+		}
+		array, index := g.variable("array"), g.variable("i")
+		elem, ok := g.assign(g.index(target, index), typ.Elem(), array+"["+index+"]")
+		if !ok {
+			return nil, false
+		}
+		g.use("strings")
+		lines := []string{"if " + array + " := strings.Split(" + source + ", \",\"); len(" + array + "[0]) > 0 {",
+			target + " = make(" + expr + ", len(" + array + "))"}
+		if len(elem) > 0 {
+			lines = append(append(append(lines, "for "+index+" := range "+array+" {"), elem...), "}")
+		}
+		return append(lines, "}"), true
+	case reflect.Ptr:
+		expr, ok := g.typeExpr(typ.Elem())
+		if !ok {
+			return nil, false
+		}
+		pointer := g.variable("p")
+		elem, ok := g.assign("*"+pointer, typ.Elem(), source)
+		lines := append([]string{"{", pointer + " := new(" + expr + ")"}, elem...)
+		return append(lines, target+" = "+pointer, "}"), ok
+	default:
+		//the kinds are ignored by converter
+		return nil, true
+	}
+	g.use("strconv")
+	value := g.variable("n")
+	base := strings.TrimPrefix(strings.SplitN(parser, "(", 2)[0], "strconv.Parse")
+	return []string{"{", value + ", _ := " + parser, target + " = " + g.convert(typ, strings.ToLower(base)+"64", value), "}"}, true
+}
+
+//convert the conversion of value (in type of base) to type
+func (g *dispatchGenerator) convert(typ reflect.Type, base string, value string) string {
+	expr, _ := g.typeExpr(typ)
+	if expr == base {
+		return value
+	}
+	return expr + "(" + value + ")"
+}
+
+func (g *dispatchGenerator) index(target string, index string) string {
+	if strings.HasPrefix(target, "*") {
+		target = "(" + target + ")"
+	}
+	return target + "[" + index + "]"
+}
+
+//typeExpr the type expression in generated package, false if it cannot be referenced
+func (g *dispatchGenerator) typeExpr(typ reflect.Type) (string, bool) {
+	if name := typ.Name(); len(name) > 0 {
+		switch {
+		case len(typ.PkgPath()) == 0 || typ.PkgPath() == g.pkgPath:
+			return name, true
+		case strings.ToLower(name[:1]) == name[:1] || strings.ContainsAny(name, "[,"):
+			return "", false
+		}
+		return g.importOf(typ) + "." + name, true
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem, ok := g.typeExpr(typ.Elem())
+		return "*" + elem, ok
+	case reflect.Slice:
+		elem, ok := g.typeExpr(typ.Elem())
+		return "[]" + elem, ok
+	case reflect.Array:
+		elem, ok := g.typeExpr(typ.Elem())
+		return "[" + strconv.Itoa(typ.Len()) + "]" + elem, ok
+	case reflect.Map:
+		key, keyOk := g.typeExpr(typ.Key())
+		elem, ok := g.typeExpr(typ.Elem())
+		return "map[" + key + "]" + elem, keyOk && ok
+	case reflect.Interface:
+		return "interface{}", typ.NumMethod() == 0
+	}
+	return "", false
+}
+
+//importOf the alias of the package of named type
+func (g *dispatchGenerator) importOf(typ reflect.Type) string {
+	if alias, existed := g.imports[typ.PkgPath()]; existed {
+		return alias
+	}
+	alias := strings.SplitN(typ.String(), ".", 2)[0]
+	for index := 2; g.aliases[alias]; index++ {
+		alias = fmt.Sprintf("%s%d", strings.TrimRight(alias, "0123456789"), index)
+	}
+	g.imports[typ.PkgPath()], g.aliases[alias] = alias, true
+	return alias
+}
+
+//snapshot the copies of imports and aliases
+func (g *dispatchGenerator) snapshot() (map[string]string, map[string]bool) {
+	imports, aliases := map[string]string{}, map[string]bool{}
+	for path, alias := range g.imports {
+		imports[path], aliases[alias] = alias, true
+	}
+	return imports, aliases
+}
+
+//use import the standard package
+func (g *dispatchGenerator) use(path string) {
+	if _, existed := g.imports[path]; !existed {
+		g.imports[path], g.aliases[path] = path, true
+	}
+}
+
+func isStandard(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+//variable the unique variable name of generated function
+func (g *dispatchGenerator) variable(prefix string) string {
+	g.counter++
+	return prefix + strconv.Itoa(g.counter)
+}
@@ -0,0 +1,160 @@
+package webapi
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+type (
+	//DocsConfig Configuration of API documentation page
+	DocsConfig struct {
+		//UI The documentation UI, "swagger" (default) or "redoc"
+		UI string
+
+		//Title The title of page, default is "API Documentation"
+		Title string
+
+		//SpecURL The URL of OpenAPI document (see ServeOpenAPI), default is "/openapi.json"
+		SpecURL string
+
+		//AssetsURL The base URL of UI scripts and styles, the vendored assets (see cmd/docsassets) are served under
+		//the page path (e.g. /docs/assets) by default, otherwise the pinned versions on public CDN are used.
+		//Set it to override them (e.g. self-hosted assets for offline environments).
+		AssetsURL string
+
+		//Auth The middleware to protect the documentation page
+		Auth Middleware
+
+		//Disabled The documentation page will not be registered (e.g. in production)
+		Disabled bool
+	}
+
+	//docsFile the vendored asset of documentation UI
+	docsFile struct {
+		contentType string
+		content     string
+	}
+)
+
+//go:generate go run ./cmd/docsassets -o docs_assets.go
+
+var (
+	docsTemplates = map[string]*template.Template{
+		"swagger": template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.AssetsURL}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.AssetsURL}}/swagger-ui-bundle.js"></script>
+<script>
+window.onload = function () {
+	window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui" });
+};
+</script>
+</body>
+</html>`)),
+		"redoc": template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+</head>
+<body>
+<redoc spec-url="{{.SpecURL}}"></redoc>
+<script src="{{.AssetsURL}}/redoc.standalone.js"></script>
+</body>
+</html>`)),
+	}
+
+	//docsAssets the asset files of UI
+	docsAssets = map[string][]string{
+		"swagger": {"swagger-ui.css", "swagger-ui-bundle.js"},
+		"redoc":   {"redoc.standalone.js"},
+	}
+
+	//docsCDN the pinned versions of UI on public CDN, used if the assets are not vendored
+	docsCDN = map[string]string{
+		"swagger": "https://unpkg.com/swagger-ui-dist@5.17.14",
+		"redoc":   "https://unpkg.com/redoc@2.1.5/bundles",
+	}
+
+	//docsFiles the vendored assets by UI and file name, generated into docs_assets.go by cmd/docsassets
+	docsFiles = map[string]docsFile{}
+)
+
+//ServeDocs Register the Swagger UI (or Redoc) page bound to the OpenAPI document,
+//nothing will be registered if the page is disabled
+func (host *Host) ServeDocs(path string, conf DocsConfig) error {
+	if conf.Disabled {
+		return nil
+	}
+	if conf.UI = strings.ToLower(conf.UI); len(conf.UI) == 0 {
+		conf.UI = "swagger"
+	}
+	tpl, supported := docsTemplates[conf.UI]
+	if !supported {
+		return host.docsError(errors.New("unsupported documentation ui '" + conf.UI + "'"))
+	}
+	if len(conf.Title) == 0 {
+		conf.Title = "API Documentation"
+	}
+	if len(conf.SpecURL) == 0 {
+		conf.SpecURL = "/openapi.json"
+	}
+	var middlewares []Middleware
+	if conf.Auth != nil {
+		middlewares = append(middlewares, conf.Auth)
+	}
+	if len(conf.AssetsURL) == 0 {
+		if _, vendored := docsFiles[conf.UI+"/"+docsAssets[conf.UI][0]]; !vendored {
+			conf.AssetsURL = docsCDN[conf.UI]
+		} else {
+			assets := strings.TrimRight(path, "/") + "/assets"
+			for _, name := range docsAssets[conf.UI] {
+				file := docsFiles[conf.UI+"/"+name]
+				if err := host.AddEndpoint(http.MethodGet, assets+"/"+name, func(ctx *Context) {
+					ctx.ResponseHeader().Set("Content-Type", file.contentType)
+					ctx.Write(http.StatusOK, []byte(file.content))
+				}, middlewares...); err != nil {
+					return err
+				}
+			}
+			//absolute with the base path of group, the page might be requested with trailing slash
+			conf.AssetsURL = host.docsPath(assets)
+		}
+	}
+	conf.AssetsURL = strings.TrimRight(conf.AssetsURL, "/")
+	var page bytes.Buffer
+	if err := tpl.Execute(&page, conf); err != nil {
+		return host.docsError(err)
+	}
+	return host.AddEndpoint(http.MethodGet, path, func(ctx *Context) {
+		ctx.ResponseHeader().Set("Content-Type", "text/html; charset=utf-8")
+		ctx.Write(http.StatusOK, page.Bytes())
+	}, middlewares...)
+}
+
+//docsPath the absolute path of endpoint in the current group
+func (host *Host) docsPath(path string) string {
+	host.mutex.RLock()
+	defer host.mutex.RUnlock()
+	return "/" + formatPath(strings.Join(append(append([]string{}, host.paths...), formatPath(path)), "/"))
+}
+
+//docsError record the error of documentation page
+func (host *Host) docsError(err error) error {
+	host.mutex.Lock()
+	defer host.mutex.Unlock()
+	host.initCheck()
+	host.errList = append(host.errList, err)
+	return err
+}
@@ -1,7 +1,6 @@
 package webapi
 
 import (
-	"container/list"
 	"errors"
 	"strconv"
 	"strings"
@@ -16,24 +15,27 @@ type (
 		Fallback func(string, int) (string, error)
 	}
 
-	//search keyword
-	keyword struct {
-		text  string
-		times int
+	//segment the bounds of path segment and its matching state
+	segment struct {
+		start int
+		end   int
+		node  *endpoint //the node which the segment is looked up in
+		times int       //the times of trying (1 means the segment itself, the others are fallbacks)
 	}
 
-	//search stack (actual working object)
-	stack struct {
-		current  *keyword
-		node     *endpoint
-		history  *list.List //[]*keyword
-		queue    *list.List //[]*keyword
-		args     *list.List
-		lower    bool
+	//matcher the path and the matching segments (actual working object)
+	matcher struct {
+		path     string
+		keys     string //lookup keys which have the same bounds as path
+		lower    bool   //lower the segments one by one if the keys are not available
 		fallback func(string, int) (string, error)
+		trace    *MatchTrace
 	}
 )
 
+//maxSegments the segments are matched in fixed buffer unless the path is deeper
+const maxSegments = 32
+
 func (n *endpoint) setVal(value interface{}, path ...string) (err error) {
 	if n.nodes == nil {
 		n.nodes = map[string]*endpoint{}
@@ -75,110 +77,148 @@ func (n *endpoint) Add(path string, value interface{}) (err error) {
 	return
 }
 
-//Search Get the endpoint value via keyword list
-func (n endpoint) search(lower bool, path ...string) (value interface{}, args []string) {
-	if len(path) == 0 {
-		path = []string{""}
-	}
-	var queue = list.New()
-	for _, p := range path[1:] {
-		queue.PushBack(&keyword{text: p})
-	}
-	return (&stack{
-		current:  &keyword{text: path[0]},
-		history:  list.New(),
-		args:     list.New(),
-		queue:    queue,
-		node:     &n,
-		lower:    lower,
-		fallback: n.Fallback,
-	}).search()
+//Search Get the endpoint value via path and its lookup keys (the lowered path or path itself),
+//the arguments are appended to args
+func (n *endpoint) Search(path string, keys string, args []string) (interface{}, []string) {
+	return n.explain(path, keys, nil, args)
 }
 
-func (n endpoint) Search(path string, lower bool) (value interface{}, args []string) {
+//explain search the path and record the steps into trace if it is not nil
+func (n *endpoint) explain(path string, keys string, trace *MatchTrace, args []string) (interface{}, []string) {
 	if n.nodes == nil {
-		return nil, nil
+		return nil, args
 	}
-	var testPath = path
-	if lower {
-		testPath = strings.ToLower(testPath)
+	obj, existed := n.nodes[keys]
+	if trace != nil {
+		step := MatchStep{Segment: path, Key: keys, Result: "static"}
+		if !existed {
+			step.Result = "missing"
+		} else {
+			trace.Pattern = keys
+		}
+		trace.Steps = append(trace.Steps, step)
 	}
-	if obj, existed := n.nodes[testPath]; existed {
-		return obj.val, nil
+	if existed {
+		return obj.val, args
 	}
-	return n.search(lower, strings.Split(path, "/")[1:]...)
+	m := matcher{path: path, keys: keys, fallback: n.Fallback, trace: trace}
+	if len(keys) != len(path) {
+		//the bounds are changed by lowering, the segments will be lowered one by one
+		m.keys, m.lower = "", true
+	}
+	if m.fallback == nil {
+		m.fallback = defaultFallback
+	}
+	return m.search(n, args)
 }
 
-func (stack *stack) search() (value interface{}, args []string) {
-	if stack.fallback == nil {
-		stack.fallback = defaultFallback
-	}
-	var key = stack.current.text
-	if stack.lower {
-		key = strings.ToLower(key)
-	}
-	var err error
-	for stack.current.times++; stack.current.times > 1; stack.current.times++ {
-		key, err = stack.fallback(stack.current.text, stack.current.times-1)
-		if err != nil || len(key) > 0 {
-			break
+//search match the segments by depth-first search, the segment will try the key itself at first,
+//then the keys given by fallback, and backtrack to the previous segment if no key matched
+func (m *matcher) search(root *endpoint, args []string) (interface{}, []string) {
+	var buffer [maxSegments]segment
+	var segments = buffer[:0]
+	if start := strings.IndexByte(m.path, '/'); start == -1 {
+		segments = append(segments, segment{})
+	} else {
+		for start++; ; start++ {
+			end := strings.IndexByte(m.path[start:], '/')
+			if end == -1 {
+				segments = append(segments, segment{start: start, end: len(m.path)})
+				break
+			}
+			segments = append(segments, segment{start: start, end: start + end})
+			start += end
 		}
 	}
-	if err != nil {
-		if stack.history.Len() == 0 || stack.node.prior == nil {
-			return nil, nil
+	segments[0].node = root
+	var depth = 0
+	for {
+		current := &segments[depth]
+		text := m.path[current.start:current.end]
+		key := m.key(current)
+		var err error
+		for current.times++; current.times > 1; current.times++ {
+			key, err = m.fallback(text, current.times-1)
+			if err != nil || len(key) > 0 {
+				break
+			}
+			m.record(depth, current, key, "skipped")
 		}
-		stack.back()
-	}
-	if node, existed := stack.node.nodes[key]; existed {
-		if stack.queue.Len() == 0 {
-			params := []string{}
-			for stack.args.Front() != nil {
-				if arg := stack.args.Remove(stack.args.Front()).(string); len(arg) > 0 {
-					params = append(params, arg)
-				}
+		if err != nil {
+			if depth == 0 {
+				m.record(depth, current, key, "exhausted")
+				return nil, args
 			}
-			if stack.current.times > 1 {
-				params = append(params, stack.current.text)
+			m.record(depth, current, key, "backtrack")
+			//the segment will be tried from the beginning once it is reached again
+			current.times = 0
+			depth--
+			continue
+		}
+		node, existed := current.node.nodes[key]
+		switch {
+		case !existed:
+			m.record(depth, current, key, "missing")
+			continue
+		case depth < len(segments)-1:
+			m.record(depth, current, key, "descend")
+			depth++
+			segments[depth].node = node
+			continue
+		case node.val == nil:
+			m.record(depth, current, key, "no handler")
+		default:
+			m.record(depth, current, key, "selected")
+		}
+		m.trace.pattern(node)
+		for _, matched := range segments[:depth] {
+			if arg := m.path[matched.start:matched.end]; matched.times > 1 && len(arg) > 0 {
+				args = append(args, arg)
 			}
-			return node.val, params
 		}
-		stack.next(node)
+		if current.times > 1 {
+			args = append(args, text)
+		}
+		return node.val, args
 	}
-	return stack.search()
 }
 
-func (stack *stack) next(node *endpoint) {
-	stack.node = node
-	stack.history.PushFront(stack.current)
-	var arg string
-	if stack.current.times > 1 {
-		arg = stack.current.text
-	}
-	stack.args.PushBack(arg)
-	stack.current = stack.queue.Remove(stack.queue.Front()).(*keyword)
+//key the key of segment itself
+func (m *matcher) key(current *segment) string {
+	if m.lower {
+		return strings.ToLower(m.path[current.start:current.end])
+	}
+	return m.keys[current.start:current.end]
 }
 
-func (stack *stack) back() {
-	stack.node = stack.node.prior
-	// stack.current.times = 0
-	stack.queue.PushFront(stack.current)
-	stack.args.Remove(stack.args.Back())
-	stack.current = stack.history.Remove(stack.history.Back()).(*keyword)
+//record record the key tried into trace
+func (m *matcher) record(depth int, current *segment, key string, result string) {
+	if m.trace != nil {
+		m.trace.Steps = append(m.trace.Steps, MatchStep{
+			Depth:    depth,
+			Segment:  m.path[current.start:current.end],
+			Key:      key,
+			Fallback: current.times - 1,
+			Result:   result,
+		})
+	}
 }
 
 func defaultFallback(value string, times int) (string, error) {
 	switch times {
 	case 1:
-		digit, isDigit := strconv.ParseInt(value, 10, 64)
-		decimal, isDecimal := strconv.ParseFloat(value, 64)
-		if isDigit == nil && float64(digit) == decimal {
-			return "{digits}", nil
-		}
-		if isDecimal == nil {
-			return `{float}`, nil
+		if maybeNumber(value) {
+			//the texts which cannot be numbers are not parsed to avoid the syntax errors
+			digit, isDigit := strconv.ParseInt(value, 10, 64)
+			decimal, isDecimal := strconv.ParseFloat(value, 64)
+			if isDigit == nil && float64(digit) == decimal {
+				return "{digits}", nil
+			}
+			if isDecimal == nil {
+				return `{float}`, nil
+			}
 		}
-		if strings.ToLower(value) == "true" || strings.ToLower(value) == "false" {
+		if strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
 			return `{bool}`, nil
 		}
 		return "", nil
@@ -188,3 +228,23 @@ func defaultFallback(value string, times int) (string, error) {
 		return "", errors.New("")
 	}
 }
+
+//maybeNumber whether the text might be accepted by strconv.ParseFloat
+func maybeNumber(value string) bool {
+	if len(value) > 0 && (value[0] == '+' || value[0] == '-') {
+		value = value[1:]
+	}
+	if strings.EqualFold(value, "inf") || strings.EqualFold(value, "infinity") || strings.EqualFold(value, "nan") {
+		return true
+	}
+	if len(value) > 1 && value[0] == '0' && (value[1] == 'x' || value[1] == 'X') {
+		//hexadecimal mantissa
+		return true
+	}
+	for index := 0; index < len(value); index++ {
+		if char := value[index]; (char < '0' || char > '9') && !strings.ContainsRune("._eE+-", rune(char)) {
+			return false
+		}
+	}
+	return len(value) > 0
+}
@@ -0,0 +1,101 @@
+package webapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	//Event Server-sent event
+	Event struct {
+		ID    string
+		Event string
+		Data  string
+		Retry time.Duration
+	}
+
+	//EventStream Server-sent events writer of context
+	EventStream struct {
+		ctx     *Context
+		flusher http.Flusher
+	}
+)
+
+//EventStream Start the server-sent events stream, the response cannot be replied in other ways after that.
+//The WriteTimeout of host is cleared for the stream, the stream should be ended by the handler or the client.
+func (ctx *Context) EventStream() (*EventStream, error) {
+	if ctx.statuscode != 0 {
+		return nil, errors.New("the last written with " + strconv.Itoa(ctx.statuscode) + " has been submitted")
+	}
+	flusher, isFlusher := ctx.w.(http.Flusher)
+	if !isFlusher {
+		return nil, errors.New("the response writer does not support flushing")
+	}
+	header := ctx.w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	//disable the buffering of reverse proxy (e.g. nginx)
+	header.Set("X-Accel-Buffering", "no")
+	//the long-lived stream is not limited by WriteTimeout (ignored if the writer does not support deadlines)
+	ctx.SetWriteDeadline(time.Time{})
+	ctx.statuscode = http.StatusOK
+	ctx.writeHeader(http.StatusOK)
+	flusher.Flush()
+	return &EventStream{
+		ctx:     ctx,
+		flusher: flusher,
+	}, nil
+}
+
+//Send Send the event and flush
+func (stream *EventStream) Send(event Event) error {
+	var builder strings.Builder
+	if len(event.ID) > 0 {
+		builder.WriteString("id: " + singleLine(event.ID) + "\n")
+	}
+	if len(event.Event) > 0 {
+		builder.WriteString("event: " + singleLine(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		builder.WriteString("retry: " + strconv.FormatInt(int64(event.Retry/time.Millisecond), 10) + "\n")
+	}
+	if len(event.Data) > 0 || len(event.Event) > 0 || len(event.ID) > 0 {
+		//the event without data (e.g. only retry) will not be dispatched by client
+		for _, line := range strings.Split(strings.Replace(event.Data, "\r\n", "\n", -1), "\n") {
+			builder.WriteString("data: " + line + "\n")
+		}
+	}
+	builder.WriteString("\n")
+	return stream.write(builder.String())
+}
+
+//Comment Send the comment line (e.g. keep-alive)
+func (stream *EventStream) Comment(text string) error {
+	return stream.write(": " + singleLine(text) + "\n\n")
+}
+
+//Done The channel closed when client is gone
+func (stream *EventStream) Done() <-chan struct{} {
+	return stream.ctx.r.Context().Done()
+}
+
+//LastEventID The Last-Event-ID header of reconnecting client
+func (stream *EventStream) LastEventID() string {
+	return stream.ctx.r.Header.Get("Last-Event-ID")
+}
+
+func (stream *EventStream) write(text string) error {
+	if _, err := stream.ctx.w.Write([]byte(text)); err != nil {
+		return err
+	}
+	stream.flusher.Flush()
+	return nil
+}
+
+//singleLine remove the line breaks which break the fields of event
+func singleLine(text string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(text)
+}
@@ -0,0 +1,43 @@
+package webapi
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+type (
+	//continueBody the body of request with "Expect: 100-continue", the client does not send the body
+	//until 100 Continue is written by net/http at the first reading
+	continueBody struct {
+		io.ReadCloser
+		requested bool
+	}
+)
+
+func (body *continueBody) Read(p []byte) (int, error) {
+	body.requested = true
+	return body.ReadCloser.Read(p)
+}
+
+//expectContinue wrap the body if the client is waiting for 100 Continue before sending body
+func (ctx *Context) expectContinue() {
+	r := ctx.r
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 || !r.ProtoAtLeast(1, 1) {
+		return
+	}
+	for _, expect := range strings.Split(r.Header.Get("Expect"), ",") {
+		if strings.EqualFold(strings.TrimSpace(expect), "100-continue") {
+			ctx.expect = &continueBody{ReadCloser: r.Body}
+			r.Body = ctx.expect
+			return
+		}
+	}
+}
+
+//ExpectsContinue Whether the client is still waiting for 100 Continue to send the body ("Expect: 100-continue"),
+//it will be sent at the first reading of body. The body is never transmitted if the request is rejected
+//(e.g. by authentication or validation middlewares) before reading, and the connection will be closed after replied.
+func (ctx *Context) ExpectsContinue() bool {
+	return ctx.expect != nil && !ctx.expect.requested
+}
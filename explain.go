@@ -0,0 +1,98 @@
+package webapi
+
+import (
+	"strings"
+)
+
+type (
+	//MatchTrace The trace of route matching explained by host.Explain
+	MatchTrace struct {
+		Method string
+		Path   string
+		//Steps The keys tried in order
+		Steps []MatchStep
+		//Matched The handler has been selected
+		Matched bool
+		//Pattern The path pattern of the selected node
+		Pattern string `json:",omitempty"`
+		//Route The route selected (nil if not matched)
+		Route *RouteInfo `json:",omitempty"`
+		//Args The path arguments passed to handler
+		Args []string `json:",omitempty"`
+		//Allowed The methods which are able to handle the path (if not matched)
+		Allowed []string `json:",omitempty"`
+		//Reason Why the handler was or wasn't selected
+		Reason string
+	}
+
+	//MatchStep The key tried on the trie node
+	MatchStep struct {
+		//Depth The index of segment
+		Depth int
+		//Segment The segment of path (the whole path for static routes)
+		Segment string
+		//Key The segment itself or the placeholder converted by fallback
+		Key string
+		//Fallback The times of fallback conversion (0 means the segment itself)
+		Fallback int
+		//Result static, missing, skipped, descend, backtrack, exhausted, no handler or selected
+		Result string
+	}
+)
+
+//Explain Explain how the request is matched with the routes of host (tenant routes are excluded),
+//the steps are the same as ServeHTTP does
+func (host *Host) Explain(method string, path string) MatchTrace {
+	trace := MatchTrace{
+		Method: strings.ToUpper(method),
+		Path:   strings.TrimSpace(path),
+	}
+	var handler interface{}
+	var keys = host.lowerPath(trace.Path)
+	host.mutex.RLock()
+	defer host.mutex.RUnlock()
+	if collection := host.handlers[trace.Method]; collection != nil {
+		handler, trace.Args = collection.explain(trace.Path, keys, &trace, nil)
+	}
+	if handler != nil {
+		trace.Matched = true
+		for index := range host.routes {
+			if route := host.routes[index]; len(route.Tenant) == 0 && route.Method == trace.Method && strings.EqualFold(route.Path, trace.Pattern) {
+				trace.Route = &route
+				break
+			}
+		}
+		trace.Reason = "selected the route " + trace.Method + " " + trace.Pattern
+		return trace
+	}
+	trace.Args = nil
+	switch {
+	case host.handlers[trace.Method] == nil:
+		trace.Reason = "no route is registered with " + trace.Method
+	case len(trace.Pattern) > 0:
+		trace.Reason = "the node " + trace.Pattern + " has no handler"
+	default:
+		trace.Reason = "no node matches the path"
+	}
+	if trace.Allowed = allowedMethods(trace.Path, keys, host.handlers); len(trace.Allowed) > 0 {
+		trace.Reason += ", the path is allowed with " + strings.Join(trace.Allowed, ", ") + " (405)"
+	}
+	return trace
+}
+
+//pattern record the path pattern of trie node
+func (trace *MatchTrace) pattern(node *endpoint) {
+	if trace == nil {
+		return
+	}
+	var keys []string
+	for ; node.prior != nil; node = node.prior {
+		for key, child := range node.prior.nodes {
+			if child == node {
+				keys = append([]string{key}, keys...)
+				break
+			}
+		}
+	}
+	trace.Pattern = "/" + strings.Join(keys, "/")
+}
@@ -0,0 +1,289 @@
+package webapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	//exampleRequest the example request of route
+	exampleRequest struct {
+		route       RouteInfo
+		path        string
+		query       url.Values
+		contentType string
+		body        []byte
+	}
+
+	harHeader struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	harPostData struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+	}
+
+	harRequest struct {
+		Method      string       `json:"method"`
+		URL         string       `json:"url"`
+		HTTPVersion string       `json:"httpVersion"`
+		Cookies     []harHeader  `json:"cookies"`
+		Headers     []harHeader  `json:"headers"`
+		QueryString []harHeader  `json:"queryString"`
+		PostData    *harPostData `json:"postData,omitempty"`
+		HeadersSize int          `json:"headersSize"`
+		BodySize    int          `json:"bodySize"`
+	}
+
+	harResponse struct {
+		Status      int         `json:"status"`
+		StatusText  string      `json:"statusText"`
+		HTTPVersion string      `json:"httpVersion"`
+		Cookies     []harHeader `json:"cookies"`
+		Headers     []harHeader `json:"headers"`
+		Content     struct {
+			Size     int    `json:"size"`
+			MimeType string `json:"mimeType"`
+		} `json:"content"`
+		RedirectURL string `json:"redirectURL"`
+		HeadersSize int    `json:"headersSize"`
+		BodySize    int    `json:"bodySize"`
+	}
+
+	harEntry struct {
+		StartedDateTime string         `json:"startedDateTime"`
+		Time            int            `json:"time"`
+		Comment         string         `json:"comment,omitempty"`
+		Request         harRequest     `json:"request"`
+		Response        harResponse    `json:"response"`
+		Cache           struct{}       `json:"cache"`
+		Timings         map[string]int `json:"timings"`
+	}
+)
+
+var (
+	//placeholderExamples example values of path placeholders
+	placeholderExamples = map[string]string{
+		"{digits}": "1",
+		"{float}":  "1.5",
+		"{bool}":   "true",
+		"{string}": "example",
+	}
+)
+
+//ExportHAR Write the example requests of routes (tenant routes are excluded) as HAR 1.2 file,
+//the "example" tags of structure fields will be used as the values of query and body
+func (host *Host) ExportHAR(w io.Writer, baseURL string) error {
+	entries := []harEntry{}
+	for _, example := range host.exampleRequests() {
+		entry := harEntry{
+			StartedDateTime: "1970-01-01T00:00:00Z",
+			Comment:         example.name(),
+			Request: harRequest{
+				Method:      example.route.Method,
+				URL:         example.url(baseURL),
+				HTTPVersion: "HTTP/1.1",
+				Cookies:     []harHeader{},
+				Headers:     []harHeader{},
+				QueryString: []harHeader{},
+				HeadersSize: -1,
+				BodySize:    len(example.body),
+			},
+			Timings: map[string]int{"send": 0, "wait": 0, "receive": 0},
+		}
+		names := make([]string, 0, len(example.query))
+		for name := range example.query {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, value := range example.query[name] {
+				entry.Request.QueryString = append(entry.Request.QueryString, harHeader{name, value})
+			}
+		}
+		if example.body != nil {
+			entry.Request.Headers = append(entry.Request.Headers, harHeader{"Content-Type", example.contentType})
+			entry.Request.PostData = &harPostData{MimeType: example.contentType, Text: string(example.body)}
+		}
+		entry.Response = harResponse{
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harHeader{},
+			Headers:     []harHeader{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+		entries = append(entries, entry)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{
+		"log": map[string]interface{}{
+			"version": "1.2",
+			"creator": map[string]string{"name": "webapi", "version": "1.0"},
+			"entries": entries,
+		},
+	})
+}
+
+//ExportCurl Write the example requests of routes (tenant routes are excluded) as shell script of curl commands
+func (host *Host) ExportCurl(w io.Writer, baseURL string) error {
+	if _, err := io.WriteString(w, "#!/bin/sh\n"); err != nil {
+		return err
+	}
+	for _, example := range host.exampleRequests() {
+		command := "\n# " + example.name() + "\ncurl -X " + example.route.Method + " " + shellQuote(example.url(baseURL))
+		if example.body != nil {
+			command += " \\\n  -H " + shellQuote("Content-Type: "+example.contentType) + " \\\n  --data-raw " + shellQuote(string(example.body))
+		}
+		if _, err := io.WriteString(w, command+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//exampleRequests create example requests of routes
+func (host *Host) exampleRequests() []*exampleRequest {
+	host.initCheck()
+	contentType := host.conf.DefaultContentType
+	serializer := host.Serializer(contentType)
+	var examples []*exampleRequest
+	for _, route := range host.Report() {
+		if len(route.Tenant) > 0 {
+			continue
+		}
+		segments := strings.Split(route.Path, "/")
+		for index, segment := range segments {
+			if value, isPlaceholder := placeholderExamples[segment]; isPlaceholder {
+				segments[index] = value
+			}
+		}
+		example := &exampleRequest{
+			route: route,
+			path:  strings.Join(segments, "/"),
+			query: url.Values{},
+		}
+		if route.Query != nil {
+			for _, field := range queryFields(route.Query) {
+				if value := exampleOf(field.Type, field.Tag.Get("example"), 0); value != nil {
+					if kind := reflect.TypeOf(value).Kind(); kind != reflect.Map && kind != reflect.Slice {
+						example.query.Set(field.Name, fmt.Sprint(value))
+					}
+				}
+			}
+		}
+		if route.Body != nil && serializer != nil {
+			if data, err := serializer.Marshal(exampleOf(route.Body, "", 0)); err == nil {
+				example.contentType, example.body = serializer.ContentType(), data
+			}
+		}
+		examples = append(examples, example)
+	}
+	return examples
+}
+
+func (example *exampleRequest) name() string {
+	if len(example.route.Controller) > 0 {
+		return example.route.Controller + "." + example.route.Action
+	}
+	return example.route.Method + " " + example.route.Path
+}
+
+func (example *exampleRequest) url(baseURL string) string {
+	address := strings.TrimRight(baseURL, "/") + example.path
+	if len(example.query) > 0 {
+		address += "?" + example.query.Encode()
+	}
+	return address
+}
+
+//exampleOf create example value of type, the example tag will be used if it is not empty
+func exampleOf(typ reflect.Type, example string, depth int) interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if len(example) > 0 {
+		var value interface{}
+		if typ.Kind() == reflect.String || json.Unmarshal([]byte(example), &value) != nil {
+			return example
+		}
+		return value
+	}
+	if depth > 4 {
+		//stop the recursive structures
+		return nil
+	}
+	switch {
+	case typ == timeType:
+		return "2006-01-02T15:04:05Z"
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		return ""
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 0
+	case reflect.Float32, reflect.Float64:
+		return 0.0
+	case reflect.Slice, reflect.Array:
+		if item := exampleOf(typ.Elem(), "", depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case reflect.Map:
+		if item := exampleOf(typ.Elem(), "", depth+1); item != nil {
+			return map[string]interface{}{"key": item}
+		}
+		return map[string]interface{}{}
+	case reflect.Struct:
+		object := map[string]interface{}{}
+		exampleFields(typ, object, depth)
+		return object
+	}
+	return nil
+}
+
+//exampleFields set the example values of fields with the rules of encoding/json
+func exampleFields(typ reflect.Type, object map[string]interface{}, depth int) {
+	for index := 0; index < typ.NumField(); index++ {
+		field := typ.Field(index)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && len(tag[0]) == 0 && fieldType.Kind() == reflect.Struct {
+			exampleFields(fieldType, object, depth)
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		name := tag[0]
+		if len(name) == 0 {
+			name = field.Name
+		}
+		if _, existed := object[name]; !existed {
+			object[name] = exampleOf(field.Type, field.Tag.Get("example"), depth+1)
+		}
+	}
+}
+
+//shellQuote quote the text for POSIX shell
+func shellQuote(text string) string {
+	return "'" + strings.Replace(text, "'", `'\''`, -1) + "'"
+}
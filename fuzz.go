@@ -0,0 +1,105 @@
+package webapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+var (
+	//fuzzValues the edge values of query fields in corpus
+	fuzzValues = []string{"", "-1", "18446744073709551616", "1e309", "NaN", "TRUE", "a,,b", "%00"}
+)
+
+//FuzzBindQuery Fuzz entry point (go-fuzz style) of query binding, data is bound as raw query into the type of target
+//in the same way as handlers (including Check), the bound value will be assigned to target if it is a pointer.
+//It returns 1 if data is accepted and 0 if rejected, panics are the findings.
+func FuzzBindQuery(target interface{}, data []byte) int {
+	typ := reflect.TypeOf(target)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.URL.RawQuery = string(data)
+	return fuzzBind(&Context{r: r}, target, newParam(typ, false, true))
+}
+
+//FuzzBindBody Fuzz entry point (go-fuzz style) of body binding with the serializer of content type,
+//the behaviours are the same as FuzzBindQuery
+func FuzzBindBody(target interface{}, contentType string, data []byte) int {
+	serializer := Serializers[strings.ToLower(contentType)]
+	if serializer == nil {
+		panic("the serializer of " + contentType + " is not registered")
+	}
+	r := httptest.NewRequest("POST", "/", strings.NewReader(string(data)))
+	r.Header.Set("Content-Type", contentType)
+	return fuzzBind(&Context{r: r, Deserializer: serializer}, target, newParam(reflect.TypeOf(target), true, false))
+}
+
+func fuzzBind(ctx *Context, target interface{}, p *param) int {
+	args, err := ctx.analyseParams(nil, []*param{p})
+	if err != nil {
+		return 0
+	}
+	if value := reflect.ValueOf(target); value.Kind() == reflect.Ptr && !value.IsNil() {
+		bound := args[0]
+		if bound.Kind() == reflect.Ptr {
+			bound = bound.Elem()
+		}
+		if value.Elem().Type() == bound.Type() {
+			value.Elem().Set(bound)
+		}
+	}
+	return 1
+}
+
+//QueryCorpus Seed inputs of FuzzBindQuery generated from the fields of target (example tags are used)
+func QueryCorpus(target interface{}) [][]byte {
+	corpus := [][]byte{{}}
+	example := url.Values{}
+	for _, field := range queryFields(reflect.TypeOf(target)) {
+		if value := exampleOf(field.Type, field.Tag.Get("example"), 0); value != nil {
+			example.Set(field.Name, strings.Trim(fmt.Sprint(value), "[]"))
+		}
+		for _, value := range fuzzValues {
+			corpus = append(corpus, []byte(url.QueryEscape(field.Name)+"="+value))
+		}
+	}
+	if len(example) > 0 {
+		corpus = append(corpus, []byte(example.Encode()))
+	}
+	return corpus
+}
+
+//BodyCorpus Seed inputs of FuzzBindBody generated from the example of target marshaled with the serializer of content type
+func BodyCorpus(target interface{}, contentType string) [][]byte {
+	corpus := [][]byte{{}, []byte("null"), []byte("{}"), []byte("[]")}
+	if serializer := Serializers[strings.ToLower(contentType)]; serializer != nil {
+		if data, err := serializer.Marshal(exampleOf(reflect.TypeOf(target), "", 0)); err == nil {
+			corpus = append(corpus, data)
+		}
+	}
+	return corpus
+}
+
+//WriteCorpus Write the seed inputs as the corpus files of "go test -fuzz" (e.g. testdata/fuzz/FuzzUser)
+func WriteCorpus(dir string, corpus [][]byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, data := range corpus {
+		content := []byte(fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", data))
+		sum := sha256.Sum256(content)
+		if err := ioutil.WriteFile(filepath.Join(dir, hex.EncodeToString(sum[:8])), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
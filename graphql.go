@@ -0,0 +1,299 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type (
+	//GraphQLExecutor Executor of GraphQL schema (e.g. an adapter of graphql library)
+	GraphQLExecutor interface {
+		//Execute Execute the request and return the result which will be replied with serializer
+		Execute(ctx context.Context, req *GraphQLRequest) interface{}
+	}
+
+	//GraphQLRequest GraphQL request
+	GraphQLRequest struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName,omitempty"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+		Extensions    map[string]interface{} `json:"extensions,omitempty"`
+
+		//Context The context of HTTP request
+		Context *Context `json:"-"`
+	}
+
+	//GraphQLOptions Options of GraphQL endpoint
+	GraphQLOptions struct {
+		//DisableGET Only POST requests will be accepted
+		DisableGET bool
+
+		//AllowGETMutations The mutations are accepted by GET requests, they are rejected with 405 by default
+		//to prevent cross-site requests (e.g. img and link) from performing mutations
+		AllowGETMutations bool
+
+		//MaxMemory The maximum bytes of multipart files stored in memory, default is 32MB
+		MaxMemory int64
+
+		//Middlewares The middlewares of GraphQL endpoint (e.g. auth, tracing)
+		Middlewares []Middleware
+	}
+)
+
+//GraphQL Register GraphQL endpoint with GET and POST handling,
+//the multipart request (https://github.com/jaydenseric/graphql-multipart-request-spec) is supported,
+//uploaded files will be set into variables as *multipart.FileHeader
+func (host *Host) GraphQL(path string, schema GraphQLExecutor, opts GraphQLOptions) error {
+	if opts.MaxMemory <= 0 {
+		opts.MaxMemory = 32 << 20
+	}
+	handler := func(ctx *Context) {
+		requests, batch, err := parseGraphQLRequests(ctx, opts)
+		if err != nil {
+			ctx.ReplyError(errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+		results := make([]interface{}, len(requests))
+		for index, req := range requests {
+			req.Context = ctx
+			results[index] = schema.Execute(ctx.GetRequest().Context(), req)
+		}
+		if ctx.statuscode != 0 {
+			//replied by executor
+			return
+		}
+		if batch {
+			ctx.Reply(http.StatusOK, results)
+		} else {
+			ctx.Reply(http.StatusOK, results[0])
+		}
+	}
+	if !opts.DisableGET {
+		if err := host.AddEndpoint(http.MethodGet, path, handler, opts.Middlewares...); err != nil {
+			return err
+		}
+	}
+	return host.AddEndpoint(http.MethodPost, path, handler, opts.Middlewares...)
+}
+
+//parseGraphQLRequests parse requests from query, body or multipart form
+func parseGraphQLRequests(ctx *Context, opts GraphQLOptions) ([]*GraphQLRequest, bool, error) {
+	r := ctx.GetRequest()
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req := &GraphQLRequest{
+			Query:         query.Get("query"),
+			OperationName: query.Get("operationName"),
+		}
+		for name, target := range map[string]*map[string]interface{}{"variables": &req.Variables, "extensions": &req.Extensions} {
+			if value := query.Get(name); len(value) > 0 {
+				if err := json.Unmarshal([]byte(value), target); err != nil {
+					return nil, false, errors.New("invalid " + name + ": " + err.Error())
+				}
+			}
+		}
+		if !opts.AllowGETMutations && isGraphQLMutation(req.Query, req.OperationName) {
+			ctx.w.Header().Set("Allow", http.MethodPost)
+			return nil, false, NewHTTPError(http.StatusMethodNotAllowed, errors.New("mutations are not allowed by GET requests"))
+		}
+		return []*GraphQLRequest{req}, false, nil
+	}
+	mediaType := ParseMediaType(r.Header.Get("Content-Type")).Type
+	if mediaType == "multipart/form-data" {
+		return parseGraphQLMultipart(r, opts.MaxMemory)
+	}
+	body := ctx.Body()
+	if err := ctx.BodyError(); err != nil {
+		return nil, false, err
+	}
+	if ctx.BeforeReading != nil {
+		body = ctx.BeforeReading(body)
+	}
+	if mediaType == "application/graphql" {
+		return []*GraphQLRequest{{Query: string(body)}}, false, nil
+	}
+	serializer := ctx.Deserializer
+	if serializer == nil {
+		serializer = Serializers["application/json"]
+	}
+	return unmarshalGraphQLRequests(body, serializer)
+}
+
+//isGraphQLMutation whether the operation executed by request is a mutation (any of the operations if it is ambiguous),
+//the operation definitions are scanned at the top level of document without parsing
+func isGraphQLMutation(query string, operationName string) bool {
+	type operation struct {
+		typ  string
+		name string
+	}
+	var operations []operation
+	var depth, parens int
+	var header string
+	var naming bool
+	for index := 0; index < len(query); index++ {
+		switch char := query[index]; {
+		case char == '#':
+			for index < len(query) && query[index] != '\n' && query[index] != '\r' {
+				index++
+			}
+		case char == '"':
+			if strings.HasPrefix(query[index:], `"""`) {
+				//block string, \""" is escaped
+				end := index + 3
+				for end < len(query) && !(strings.HasPrefix(query[end:], `"""`) && query[end-1] != '\\') {
+					end++
+				}
+				index = end + 2
+				continue
+			}
+			for index++; index < len(query) && query[index] != '"'; index++ {
+				if query[index] == '\\' {
+					index++
+				}
+			}
+		case char == '{':
+			if depth == 0 && parens == 0 && len(header) == 0 {
+				//the shorthand of query
+				operations = append(operations, operation{typ: "query"})
+			}
+			if depth == 0 {
+				header, naming = "", false
+			}
+			depth++
+		case char == '}':
+			depth--
+		case char == '(':
+			parens++
+		case char == ')':
+			parens--
+		case char == '@':
+			naming = false
+		case char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z'):
+			start := index
+			for index+1 < len(query) && (query[index+1] == '_' || (query[index+1] >= '0' && query[index+1] <= '9') ||
+				(query[index+1] >= 'a' && query[index+1] <= 'z') || (query[index+1] >= 'A' && query[index+1] <= 'Z')) {
+				index++
+			}
+			if depth > 0 || parens > 0 {
+				continue
+			}
+			switch name := query[start : index+1]; {
+			case len(header) == 0 && (name == "query" || name == "mutation" || name == "subscription"):
+				header, naming = name, true
+				operations = append(operations, operation{typ: name})
+			case len(header) == 0 && name == "fragment":
+				header = name
+			case naming:
+				operations[len(operations)-1].name, naming = name, false
+			}
+		}
+	}
+	for _, operation := range operations {
+		if operation.typ == "mutation" && (len(operationName) == 0 || operation.name == operationName) {
+			return true
+		}
+	}
+	return false
+}
+
+//unmarshalGraphQLRequests unmarshal single or batch requests
+func unmarshalGraphQLRequests(body []byte, serializer Serializer) ([]*GraphQLRequest, bool, error) {
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		var requests []*GraphQLRequest
+		if err := serializer.Unmarshal(body, &requests); err != nil {
+			return nil, true, err
+		}
+		if len(requests) == 0 {
+			return nil, true, errors.New("empty batch")
+		}
+		for index, req := range requests {
+			if req == nil {
+				return nil, true, errors.New("the request " + strconv.Itoa(index) + " of batch is null")
+			}
+		}
+		return requests, true, nil
+	}
+	req := &GraphQLRequest{}
+	if err := serializer.Unmarshal(body, req); err != nil {
+		return nil, false, err
+	}
+	return []*GraphQLRequest{req}, false, nil
+}
+
+//parseGraphQLMultipart parse multipart request and set files into variables
+func parseGraphQLMultipart(r *http.Request, maxMemory int64) ([]*GraphQLRequest, bool, error) {
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		if errors.Is(err, ErrBodyTooLarge) {
+			return nil, false, NewHTTPError(http.StatusRequestEntityTooLarge, err)
+		}
+		return nil, false, err
+	}
+	requests, batch, err := unmarshalGraphQLRequests([]byte(r.FormValue("operations")), Serializers["application/json"])
+	if err != nil {
+		return nil, batch, errors.New("invalid operations: " + err.Error())
+	}
+	var mapping map[string][]string
+	if value := r.FormValue("map"); len(value) > 0 {
+		if err = json.Unmarshal([]byte(value), &mapping); err != nil {
+			return nil, batch, errors.New("invalid map: " + err.Error())
+		}
+	}
+	for name, paths := range mapping {
+		files := r.MultipartForm.File[name]
+		if len(files) == 0 {
+			return nil, batch, errors.New("file " + name + " is missing")
+		}
+		for _, path := range paths {
+			if err = setGraphQLFile(requests, batch, path, files[0]); err != nil {
+				return nil, batch, err
+			}
+		}
+	}
+	return requests, batch, nil
+}
+
+//setGraphQLFile set file into variables via object path (e.g. 0.variables.files.1)
+func setGraphQLFile(requests []*GraphQLRequest, batch bool, path string, file *multipart.FileHeader) error {
+	keys := strings.Split(path, ".")
+	req := requests[0]
+	if batch {
+		index, err := strconv.Atoi(keys[0])
+		if err != nil || index < 0 || index >= len(requests) {
+			return errors.New("invalid file path " + path)
+		}
+		req, keys = requests[index], keys[1:]
+	}
+	if len(keys) < 2 || keys[0] != "variables" || req.Variables == nil {
+		return errors.New("invalid file path " + path)
+	}
+	var container interface{} = req.Variables
+	for index, key := range keys[1:] {
+		last := index == len(keys)-2
+		switch current := container.(type) {
+		case map[string]interface{}:
+			if last {
+				current[key] = file
+				return nil
+			}
+			container = current[key]
+		case []interface{}:
+			position, err := strconv.Atoi(key)
+			if err != nil || position < 0 || position >= len(current) {
+				return errors.New("invalid file path " + path)
+			}
+			if last {
+				current[position] = file
+				return nil
+			}
+			container = current[position]
+		default:
+			return errors.New("invalid file path " + path)
+		}
+	}
+	return errors.New("invalid file path " + path)
+}
@@ -1,6 +1,8 @@
 package webapi
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -8,10 +10,20 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime/debug"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
+//lowerPathsCapacity the capacity of lowered request paths cache
+const lowerPathsCapacity = 1024
+
 var (
+	//slashes the continuous slashes and backslashes in path
+	slashes = regexp.MustCompile(`[\\/]{1,}`)
+
 	//internalControllerMethods A convenient dictionary of internal usage method fields
 	internalControllerMethods = map[string]bool{}
 
@@ -48,14 +60,53 @@ func init() {
 type (
 	//Host Service for HTTP
 	Host struct {
-		handlers map[string]*endpoint
+		handlers routeTable
 		conf     Config
 		errList  []error
+		onError  ErrorHandler
+
+		serializers map[string]Serializer
+		server      *http.Server
+		closed      bool
+		onStart     []func(context.Context) error
+		onStop      []func(context.Context) error
+		routes      []RouteInfo
+		checks      []healthCheck
+		controllers []Controller
+
+		//default body transformers for context
+		beforeReading func([]byte) []byte
+		beforeWriting func(int, []byte) []byte
+
+		//crypto the crypto service of encrypted cookies
+		crypto CryptoService
+		//authorizer the authorizer of declared permissions
+		authorizer Authorizer
+
+		//tenant overlays
+		tenants        map[string]*tenant
+		tenantResolver func(*http.Request) (string, error)
+
+		//pool of contexts
+		pool sync.Pool
+		//mutex guards the routes, tenants and middlewares between registration and serving
+		mutex sync.RWMutex
+
+		//placeholder the quoted CustomisedPlaceholder
+		placeholder string
+		//lowerPaths the lowered request paths if UseLowerLetter
+		lowerPaths *lru
+		//lookups the cached results of dynamic route lookups if RouteCacheSize is set
+		lookups *lru
+		//maxArguments the most path arguments of routes
+		maxArguments int
 
 		//Stack data
-		paths  []string
-		global httpHandler
-		mstack []Middleware
+		paths   []string
+		scope   *tenant
+		global  httpHandler
+		globals []Middleware
+		mstack  []Middleware
 	}
 
 	//Config Configuration
@@ -69,22 +120,65 @@ type (
 		//HTTPMethodTagName Specify the specific method for the endpoint, default is "options"
 		HTTPMethodTagName string
 
+		//PermissionTagName The tag declares the permissions required by actions (see SetAuthorizer), default is "permission"
+		PermissionTagName string
+
 		//CustomisedPlaceholder Used to specify where the parameters should be in the URL. The specified string will quoted by {}.
 		//E.G.: param -> {param}
 		CustomisedPlaceholder string
 
 		//AutoReport This option will display route table after successful registration
 		DisableAutoReport bool
+
+		//InsecureAdmin The admin endpoints (including pprof and config) can be enabled without auth middleware,
+		//e.g. for local development only
+		InsecureAdmin bool
+
+		//DefaultContentType The content type will be used when the request does not declare one and the reply serializer is not specified,
+		//default is "application/json"
+		DefaultContentType string
+
+		//ReadHeaderTimeout The amount of time allowed to read request headers, default is 10s (negative value means no timeout)
+		ReadHeaderTimeout time.Duration
+
+		//ReadTimeout The maximum duration for reading the entire request, default is 60s (negative value means no timeout)
+		ReadTimeout time.Duration
+
+		//WriteTimeout The maximum duration before timing out writes of the response, default is 60s (negative value means no timeout)
+		WriteTimeout time.Duration
+
+		//IdleTimeout The maximum amount of time to wait for the next request when keep-alives are enabled, default is 120s (negative value means no timeout)
+		IdleTimeout time.Duration
+
+		//MaxHeaderBytes The maximum number of bytes the server will read parsing the request header, default is 1MB
+		MaxHeaderBytes int
+
+		//MaxBodySize The maximum bytes of request body can be read, default is 32MB (negative value means no limitation)
+		//the request will be rejected with 413 before dispatching if the declared Content-Length exceeds it
+		MaxBodySize int64
+
+		//RouteCacheSize The capacity of the cache of dynamic route lookups (method and path to the handler and arguments),
+		//default is 0 which means no cache. The cache is cleared whenever a route is registered
+		RouteCacheSize int
+
+		//TLSConfig The TLS configuration used by RunTLS (e.g. TLSModern()), default is TLSIntermediate()
+		TLSConfig *tls.Config `json:"-"`
+
+		//PanicHandler Will be invoked with the context, the recovered value and the stack trace
+		//when a panic escaped from middlewares and handlers, the client will receive 500 via the error handler
+		PanicHandler func(ctx *Context, err interface{}, stack []byte) `json:"-"`
 	}
 )
 
 //NewHost Create a new service host
 func NewHost(conf Config, middlewares ...Middleware) (host *Host) {
 	host = &Host{
-		handlers: map[string]*endpoint{},
-		conf:     conf,
-		global:   pipeline(nil, middlewares...),
-		mstack:   middlewares,
+		handlers:    routeTable{},
+		conf:        conf,
+		globals:     middlewares,
+		global:      pipeline(nil, middlewares...),
+		mstack:      middlewares,
+		serializers: map[string]Serializer{},
 	}
 	if !conf.DisableAutoReport {
 		os.Stdout.WriteString("Registration Info:\r\n")
@@ -98,44 +192,154 @@ func (host *Host) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Body != nil {
 		defer r.Body.Close()
 	}
-	ctx := &Context{
-		w:            w,
-		r:            r,
-		Deserializer: Serializers[strings.Split(r.Header.Get("Content-Type"), ";")[0]],
-	}
-	collection := host.handlers[strings.ToUpper(r.Method)]
-	var run, args = host.global, []string{}
-	if collection != nil {
-		var path = strings.TrimSpace(r.URL.Path)
-		// if host.conf.UseLowerLetter {
-		// 	path = strings.ToLower(path)
-		// }
-		handler, arguments := collection.Search(path, host.conf.UseLowerLetter)
-		if handler != nil {
-			run = handler.(httpHandler)
-			args = arguments
+	ctx, _ := host.pool.Get().(*Context)
+	if ctx == nil {
+		ctx = &Context{}
+	}
+	ctx.w, ctx.r, ctx.host = w, r, host
+	ctx.mediaType = ParseMediaType(r.Header.Get("Content-Type"))
+	ctx.Deserializer = host.Serializer(ctx.mediaType.Type)
+	ctx.BeforeReading, ctx.BeforeWriting = host.beforeReading, host.beforeWriting
+	//the context will be reset and reused after the callbacks of OnFinish
+	defer host.release(ctx)
+	defer func() {
+		if err := recover(); err != nil {
+			if err == http.ErrAbortHandler {
+				//keep the behaviour of net/http
+				panic(err)
+			}
+			if host.conf.PanicHandler != nil {
+				host.conf.PanicHandler(ctx, err, debug.Stack())
+			}
+			if ctx.statuscode == 0 {
+				ctx.ReplyError(http.StatusInternalServerError, errors.New(http.StatusText(http.StatusInternalServerError)))
+			}
+		}
+	}()
+	ctx.expectContinue()
+	if limit := host.conf.MaxBodySize; limit > 0 && r.Body != nil {
+		if r.ContentLength > limit {
+			//reject before reading any content
+			ctx.ReplyError(http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+			return
+		}
+		r.Body = &limitedBody{ReadCloser: r.Body, remaining: limit}
+	}
+	if host.tenantResolver != nil {
+		var err error
+		if ctx.tenant, err = host.tenantResolver(r); err != nil {
+			ctx.ReplyError(errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+	}
+	var path, method = strings.TrimSpace(r.URL.Path), strings.ToUpper(r.Method)
+	var keys = host.lowerPath(path)
+	var handler interface{}
+	var tenant *tenant
+	var tenantMiddlewares []Middleware
+	//the lock is released before running handlers, which are allowed to register routes
+	host.mutex.RLock()
+	if cap(ctx.arguments) < host.maxArguments {
+		ctx.arguments = make([]string, 0, host.maxArguments)
+	}
+	var run, args = host.global, ctx.arguments[:0]
+	if host.tenantResolver != nil {
+		if tenant = host.tenants[ctx.tenant]; tenant != nil {
+			tenantMiddlewares = tenant.middlewares
 		}
 	}
-	if run != nil {
+	if handler, args = host.lookup(tenant, method, path, keys, args); handler != nil {
+		run = handler.(httpHandler)
+	}
+	host.mutex.RUnlock()
+	if len(tenantMiddlewares) > 0 {
+		invoke(ctx, tenantMiddlewares, run, args)
+	} else if run != nil {
 		run(ctx, args...)
 	}
 	if ctx.statuscode == 0 {
-		ctx.Reply(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+		if handler == nil {
+			host.mutex.RLock()
+			tables := []routeTable{host.handlers}
+			if tenant != nil {
+				tables = append(tables, tenant.handlers)
+			}
+			methods := allowedMethods(path, keys, tables...)
+			host.mutex.RUnlock()
+			if len(methods) > 0 {
+				//the path exists with other methods
+				ctx.w.Header().Set("Allow", strings.Join(methods, ", "))
+				ctx.ReplyError(http.StatusMethodNotAllowed, errors.New(http.StatusText(http.StatusMethodNotAllowed)))
+				return
+			}
+		}
+		ctx.ReplyError(http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+	}
+}
+
+//release write the trailers, run the callbacks of OnFinish and put the context back into pool if it is not retained
+func (host *Host) release(ctx *Context) {
+	ctx.writeTrailers()
+	for _, callback := range ctx.onFinish {
+		callback(ctx)
+	}
+	if !ctx.retained {
+		ctx.reset()
+		host.pool.Put(ctx)
+	}
+}
+
+//SetErrorHandler Set the handler for errors generated by framework (404, 400, 500 etc.)
+func (host *Host) SetErrorHandler(handler ErrorHandler) *Host {
+	host.onError = handler
+	return host
+}
+
+//RegisterSerializer Register the serializer for the content type with the host only
+func (host *Host) RegisterSerializer(contentType string, serializer Serializer) *Host {
+	if host.serializers == nil {
+		host.serializers = map[string]Serializer{}
 	}
+	host.serializers[strings.ToLower(strings.TrimSpace(contentType))] = serializer
+	return host
+}
+
+//Serializer Get the serializer of content type, the global serializers will be used if it is not registered in host
+func (host *Host) Serializer(contentType string) Serializer {
+	if contentType = strings.ToLower(strings.TrimSpace(contentType)); len(contentType) == 0 {
+		contentType = host.conf.DefaultContentType
+	}
+	if serializer, existed := host.serializers[contentType]; existed {
+		return serializer
+	}
+	return Serializers[contentType]
+}
+
+//SetBodyTransformers Set the default BeforeReading and BeforeWriting of each context, middlewares are still able to replace them
+//(the data passed to BeforeWriting might be a pooled buffer, it must be copied if retained after returned)
+func (host *Host) SetBodyTransformers(read func([]byte) []byte, write func(int, []byte) []byte) *Host {
+	host.beforeReading, host.beforeWriting = read, write
+	return host
 }
 
 //Use Add middlewares into host
 func (host *Host) Use(middlewares ...Middleware) *Host {
+	host.mutex.Lock()
+	defer host.mutex.Unlock()
 	if len(middlewares) > 0 {
 		host.mstack = append(host.mstack, middlewares...)
+		//the later middlewares wrap the earlier ones for the requests without route
+		host.globals = append(append([]Middleware{}, middlewares...), host.globals...)
+		host.global = pipeline(nil, host.globals...)
 	}
-	host.global = pipeline(host.global, middlewares...)
 	return host
 }
 
-//Group Set prefix to endpoints
+//Group Set prefix to endpoints, the registrations are safe while serving but the scope is shared by host,
+//other goroutines should not register during the register function
 func (host *Host) Group(basepath string, register func(), middlewares ...Middleware) {
 	{
+		host.mutex.Lock()
 		host.initCheck()
 		if len(basepath) > 0 && basepath[0] == '/' {
 			basepath = basepath[1:]
@@ -143,20 +347,28 @@ func (host *Host) Group(basepath string, register func(), middlewares ...Middlew
 		orginalPaths, orginalStack := host.paths, host.mstack
 		defer func() {
 			//还原栈
+			host.mutex.Lock()
 			host.mstack, host.paths = orginalStack, orginalPaths
+			host.mutex.Unlock()
 		}()
 	}
 	//处理基地址问题
-	host.mstack = append(host.mstack, middlewares...)
-	host.paths = append(host.paths, basepath)
+	//the stacks are copied to avoid overwriting the spare capacity shared with the outer scope
+	host.mstack = append(append([]Middleware{}, host.mstack...), middlewares...)
+	host.paths = append(append([]string{}, host.paths...), basepath)
+	//the lock is released for the registrations in scope
+	host.mutex.Unlock()
 	register()
 }
 
 //Register Register the controller with the host
 func (host *Host) Register(basepath string, controller Controller, middlewares ...Middleware) (err error) {
-	var paths = append(host.paths, basepath)
+	host.mutex.Lock()
+	defer host.mutex.Unlock()
+	var paths = append(append([]string{}, host.paths...), basepath)
 	{
 		host.initCheck()
+		host.controllers = append(host.controllers, controller)
 		defer func() {
 			if err != nil {
 				host.errList = append(host.errList, err)
@@ -164,7 +376,7 @@ func (host *Host) Register(basepath string, controller Controller, middlewares .
 		}()
 		if len(host.mstack) > 0 {
 			//stack data will used to set prior middlewares
-			middlewares = append(host.mstack, middlewares...)
+			middlewares = append(append([]Middleware{}, host.mstack...), middlewares...)
 		}
 	}
 	typ := reflect.TypeOf(controller)
@@ -180,6 +392,8 @@ func (host *Host) Register(basepath string, controller Controller, middlewares .
 		return
 	}
 	paths = append(paths, controllerbasepath)
+	permissions := host.permissionsOf(typ)
+	handlers := host.currentRoutes()
 	for index := 0; index < typ.NumMethod(); index++ {
 		//register all open methods.
 		method := typ.Method(index)
@@ -194,8 +408,16 @@ func (host *Host) Register(basepath string, controller Controller, middlewares .
 		if err != nil {
 			return
 		}
-		for option, endpoints := range methods {
-			handler := ep.MakeHandler()
+		options := make([]string, 0, len(methods))
+		for option := range methods {
+			options = append(options, option)
+		}
+		//keep the registration (and the auto-report) in stable order
+		sort.Strings(options)
+		for _, option := range options {
+			endpoints := methods[option]
+			required := append(append([]string(nil), permissions...), ep.permissions...)
+			handler := authorized(ep.MakeHandler(), required)
 			for i, path := range endpoints {
 				if len(path) > 0 {
 					path = strings.Join(append(paths, path), "/")
@@ -206,10 +428,19 @@ func (host *Host) Register(basepath string, controller Controller, middlewares .
 				if err != nil {
 					return
 				}
-				if _, existed := host.handlers[option]; !existed {
-					host.handlers[option] = &endpoint{}
+				if _, existed := handlers[option]; !existed {
+					handlers[option] = &endpoint{}
+				}
+				info := RouteInfo{
+					Method:      option,
+					Path:        path,
+					Controller:  controllerName(typ),
+					Action:      method.Name,
+					Permissions: required,
 				}
-				if err = host.handlers[option].Add(path, pipeline(handler, middlewares...)); err != nil {
+				info.setTypes(ep)
+				route := host.newRoute(info, middlewares)
+				if err = handlers[option].Add(path, route.serve(pipeline(handler, middlewares...))); err != nil {
 					if index > 0 {
 						//if the alias is already existed,
 						//jump it directly.
@@ -217,6 +448,7 @@ func (host *Host) Register(basepath string, controller Controller, middlewares .
 					}
 					return
 				}
+				host.addRoute(route)
 				if !host.conf.DisableAutoReport {
 					//only 4 letters will be displayed if autoreport
 					methodprefix := fmt.Sprintf("[%4s]", smallerMethod(option))
@@ -234,6 +466,8 @@ func (host *Host) Register(basepath string, controller Controller, middlewares .
 
 //AddEndpoint Register the endpoint with the host
 func (host *Host) AddEndpoint(method string, path string, handler HTTPHandler, middlewares ...Middleware) (err error) {
+	host.mutex.Lock()
+	defer host.mutex.Unlock()
 	{
 		host.initCheck()
 		path = strings.Join(append(host.paths, formatPath(path, true)), "/")
@@ -243,16 +477,24 @@ func (host *Host) AddEndpoint(method string, path string, handler HTTPHandler, m
 			}
 		}()
 	}
-	if _, existed := host.handlers[method]; !existed {
-		host.handlers[method] = &endpoint{}
+	handlers := host.currentRoutes()
+	if _, existed := handlers[method]; !existed {
+		handlers[method] = &endpoint{}
 	}
 	if len(host.mstack) > 0 {
-		middlewares = append(host.mstack, middlewares...)
+		middlewares = append(append([]Middleware{}, host.mstack...), middlewares...)
 	}
 	path = "/" + path
-	err = host.handlers[method].Add(path, pipeline(func(context *Context, _ ...string) {
+	route := host.newRoute(RouteInfo{
+		Method: method,
+		Path:   path,
+	}, middlewares)
+	err = handlers[method].Add(path, route.serve(pipeline(func(context *Context, _ ...string) {
 		handler(context)
-	}, middlewares...))
+	}, middlewares...)))
+	if err == nil {
+		host.addRoute(route)
+	}
 	if !host.conf.DisableAutoReport {
 		if len(path) == 0 {
 			path = "/"
@@ -262,9 +504,11 @@ func (host *Host) AddEndpoint(method string, path string, handler HTTPHandler, m
 	return
 }
 
-//Errors Return server build time error
+//Errors Return server build time error (a copy, it is safe to call while registering)
 func (host *Host) Errors() []error {
-	return host.errList
+	host.mutex.RLock()
+	defer host.mutex.RUnlock()
+	return append([]error(nil), host.errList...)
 }
 
 func (host *Host) initCheck() {
@@ -274,34 +518,91 @@ func (host *Host) initCheck() {
 	if len(host.conf.HTTPMethodTagName) == 0 {
 		host.conf.HTTPMethodTagName = "options"
 	}
+	if len(host.conf.PermissionTagName) == 0 {
+		host.conf.PermissionTagName = "permission"
+	}
 	if len(host.conf.CustomisedPlaceholder) == 0 {
 		host.conf.CustomisedPlaceholder = "param"
 	}
+	if len(host.placeholder) == 0 {
+		host.placeholder = "{" + host.conf.CustomisedPlaceholder + "}"
+	}
+	if host.conf.UseLowerLetter && host.lowerPaths == nil {
+		host.lowerPaths = newLRU(lowerPathsCapacity)
+	}
+	if host.conf.RouteCacheSize > 0 && host.lookups == nil {
+		host.lookups = newLRU(host.conf.RouteCacheSize)
+	}
+	if len(host.conf.DefaultContentType) == 0 {
+		host.conf.DefaultContentType = "application/json"
+	}
+	if host.conf.ReadHeaderTimeout == 0 {
+		host.conf.ReadHeaderTimeout = 10 * time.Second
+	}
+	if host.conf.ReadTimeout == 0 {
+		host.conf.ReadTimeout = 60 * time.Second
+	}
+	if host.conf.WriteTimeout == 0 {
+		host.conf.WriteTimeout = 60 * time.Second
+	}
+	if host.conf.IdleTimeout == 0 {
+		host.conf.IdleTimeout = 120 * time.Second
+	}
+	if host.conf.MaxBodySize == 0 {
+		host.conf.MaxBodySize = 32 << 20
+	}
+	if host.conf.MaxHeaderBytes <= 0 {
+		host.conf.MaxHeaderBytes = http.DefaultMaxHeaderBytes
+	}
 	if host.handlers == nil {
-		host.handlers = map[string]*endpoint{}
+		host.handlers = routeTable{}
 		host.errList = make([]error, 0)
 	}
 }
 
-//pipeline create httpHandler with handler and middlewares (Recursive)
+type (
+	//cursor the middlewares and the handler of a request, the steps are bound to their own indexes
+	//so that next invoked later (e.g. by a goroutine after the middleware returned) still resumes at the right position
+	cursor struct {
+		middlewares []Middleware
+		handler     httpHandler
+		args        []string
+	}
+)
+
+//pipeline create httpHandler which invokes the middlewares in order and then the handler
 func pipeline(handler httpHandler, middlewares ...Middleware) httpHandler {
 	if len(middlewares) == 0 {
 		return handler
 	}
-	middleware := middlewares[len(middlewares)-1]
-	middlewares = middlewares[:len(middlewares)-1]
-	complexHandler := func(ctx *Context, args ...string) {
-		//create a composite pipeline using middleware
-		middleware.Invoke(ctx, func(arguments ...string) HTTPHandler {
-			if handler == nil {
-				return func(*Context) {}
-			}
-			return func(context *Context) {
-				handler(context, arguments...)
-			}
-		}(args...))
+	//the slice might be shared with the stack of host
+	middlewares = append([]Middleware{}, middlewares...)
+	return func(ctx *Context, args ...string) {
+		invoke(ctx, middlewares, handler, args)
+	}
+}
+
+//invoke run the middlewares by index instead of nested closures
+func invoke(ctx *Context, middlewares []Middleware, handler httpHandler, args []string) {
+	c := &cursor{middlewares: middlewares, handler: handler, args: args}
+	c.run(ctx, 0)
+}
+
+//step the next of the middleware before index
+func (c *cursor) step(index int) HTTPHandler {
+	return func(ctx *Context) {
+		c.run(ctx, index)
+	}
+}
+
+func (c *cursor) run(ctx *Context, index int) {
+	if index < len(c.middlewares) {
+		c.middlewares[index].Invoke(ctx, c.step(index+1))
+		return
+	}
+	if c.handler != nil {
+		c.handler(ctx, c.args...)
 	}
-	return pipeline(complexHandler, middlewares...)
 }
 
 func getReplacer(typ reflect.Type) (string, error) {
@@ -397,6 +698,13 @@ func (host *Host) getMethodArguments(method reflect.Method, contextArgs []reflec
 		ContextArgs: contextArgs,
 		Context:     method.Type.In(0),
 		Args:        make([]*param, 0),
+		dispatch:    dispatchOf(method.Type.In(0), method.Name),
+	}
+	for _, arg := range contextArgs {
+		ep.initArgs = append(ep.initArgs, converterOf(arg))
+	}
+	if init, existed := ep.Context.MethodByName("Init"); existed && contextArgs != nil {
+		ep.initIndex = init.Index
 	}
 	var paths []string
 	var methods []string
@@ -411,33 +719,26 @@ func (host *Host) getMethodArguments(method reflect.Method, contextArgs []reflec
 			argPaths, argMethods := host.getMethodPath(arg)
 			paths = append(paths, argPaths...)
 			methods = append(methods, argMethods...)
+			ep.permissions = append(ep.permissions, host.permissionsOf(arg)...)
 		}
 		if isBody {
 			if hasBody {
 				return nil, nil, nil, errors.New("cannot assign 2 sets from body")
 			}
-			ep.Args = append(ep.Args, &param{
-				Type:   arg,
-				isBody: true,
-			})
+			ep.Args = append(ep.Args, newParam(arg, true, false))
 			hasBody = true
 		} else if arg.Kind() == reflect.Struct {
 			if hasQuery {
 				return nil, nil, nil, errors.New("cannot assign 2 sets from query")
 			}
-			ep.Args = append(ep.Args, &param{
-				Type:    arg,
-				isQuery: true,
-			})
+			ep.Args = append(ep.Args, newParam(arg, false, true))
 			hasQuery = true
 		} else {
 			name, err := getReplacer(arg)
 			if err != nil {
 				return nil, nil, nil, err
 			}
-			ep.Args = append(ep.Args, &param{
-				Type: arg,
-			})
+			ep.Args = append(ep.Args, newParam(arg, false, false))
 			appendix = append(appendix, name)
 		}
 	}
@@ -467,6 +768,9 @@ func (host *Host) getMethodArguments(method reflect.Method, contextArgs []reflec
 			methods = []string{http.MethodGet}
 		}
 	}
+	if ep.arity = len(ep.Args) + 1; ep.arity < len(contextArgs) {
+		ep.arity = len(contextArgs)
+	}
 	options := make(map[string][]string, len(methods))
 	var index = 0
 	for _, option := range methods {
@@ -492,19 +796,15 @@ func (host *Host) finalMethodPath(path string, appendix []string) (string, error
 	if path = "/" + filepath.ToSlash(formatPath(path)); endwithslash && len(path) > 1 {
 		path += "/"
 	}
-	for {
-		where := strings.Index(path, "{"+host.conf.CustomisedPlaceholder+"}")
-		if len(appendix) != 0 && where != -1 {
-			path = strings.Replace(path, "{"+host.conf.CustomisedPlaceholder+"}", appendix[0], 1)
-			appendix = appendix[1:]
-		} else {
+	for ; len(appendix) > 0; appendix = appendix[1:] {
+		where := strings.Index(path, host.placeholder)
+		if where == -1 {
 			break
 		}
+		path = path[:where] + appendix[0] + path[where+len(host.placeholder):]
 	}
-	if len(appendix) == 0 {
-		if strings.Contains(path, "{"+host.conf.CustomisedPlaceholder+"}") {
-			return "", errors.New("cannot match " + path + " according to the params")
-		}
+	if len(appendix) == 0 && strings.Contains(path, host.placeholder) {
+		return "", errors.New("cannot match " + path + " according to the params")
 	}
 	if suffix := strings.Join(appendix, "/"); len(suffix) > 0 {
 		path += "/" + suffix
@@ -556,8 +856,30 @@ func smallerMethod(method string) string {
 	return method
 }
 
+//lowerPath the lookup keys of request path (lowered via cache if UseLowerLetter)
+func (host *Host) lowerPath(path string) string {
+	if !host.conf.UseLowerLetter {
+		return path
+	}
+	for index := 0; index < len(path); index++ {
+		if char := path[index]; char >= 'A' && char <= 'Z' || char >= 0x80 {
+			if host.lowerPaths == nil {
+				return strings.ToLower(path)
+			}
+			if lowered, existed := host.lowerPaths.Get(path); existed {
+				return lowered.(string)
+			}
+			lowered := strings.ToLower(path)
+			host.lowerPaths.Add(path, lowered)
+			return lowered
+		}
+	}
+	//nothing to lower
+	return path
+}
+
 func formatPath(path string, skipsuffix ...bool) string {
-	path = regexp.MustCompile(`[\\/]{1,}`).ReplaceAllString(path, "/")
+	path = slashes.ReplaceAllString(path, "/")
 	path = strings.TrimLeft(path, "/")
 	if len(skipsuffix) == 0 || !skipsuffix[0] {
 		path = strings.TrimRight(path, "/")
@@ -0,0 +1,565 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	//ResourceTyper The resource type of JSON:API document, the lowercase name of structure will be used by default
+	ResourceTyper interface {
+		ResourceType() string
+	}
+
+	//Linker The links of resource (or collection), e.g. {"self": "/articles/1"}
+	Linker interface {
+		Links() map[string]string
+	}
+
+	//jsonAPISerializer JSON:API (https://jsonapi.org) documents, the field with `resource:"id"` (or named ID) is the id
+	//and the fields with `resource:"rel"` are the relationships which will be included
+	jsonAPISerializer struct{}
+
+	//halSerializer HAL (application/hal+json) documents, links are set into _links and relationships into _embedded
+	halSerializer struct{}
+
+	//negotiator select the serializer with Accept header
+	negotiator struct {
+		contentTypes []string
+	}
+
+	//resourceMeta the fields of resource
+	resourceMeta struct {
+		value      reflect.Value
+		typ        string
+		id         string
+		attributes map[string]interface{}
+		relations  map[string]reflect.Value
+	}
+
+	jsonAPIResource struct {
+		Type          string                         `json:"type"`
+		ID            string                         `json:"id,omitempty"`
+		Attributes    map[string]interface{}         `json:"attributes,omitempty"`
+		Relationships map[string]jsonAPIRelationship `json:"relationships,omitempty"`
+		Links         map[string]string              `json:"links,omitempty"`
+	}
+
+	jsonAPIIdentifier struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}
+
+	jsonAPIRelationship struct {
+		Data interface{} `json:"data"`
+	}
+
+	jsonAPIDocument struct {
+		Data     interface{}        `json:"data"`
+		Included []*jsonAPIResource `json:"included,omitempty"`
+		Links    map[string]string  `json:"links,omitempty"`
+	}
+)
+
+//EnableHypermedia Register the JSON:API (application/vnd.api+json) and HAL (application/hal+json) serializers
+//with the host only, they could be selected by Negotiate or the Content-Type of requests
+func (host *Host) EnableHypermedia() *Host {
+	return host.RegisterSerializer("application/vnd.api+json", &jsonAPISerializer{}).
+		RegisterSerializer("application/hal+json", &halSerializer{})
+}
+
+//Negotiate The middleware which selects the response serializer, e.g. per controller via Register.
+//The only content type will be always used, otherwise the Accept header will be negotiated (the first one is default),
+//"406 Not Acceptable" will be replied if none of them is acceptable.
+func Negotiate(contentTypes ...string) Middleware {
+	return &negotiator{contentTypes: contentTypes}
+}
+
+func (n *negotiator) Invoke(ctx *Context, next HTTPHandler) {
+	if len(n.contentTypes) == 0 {
+		next(ctx)
+		return
+	}
+	contentType := n.contentTypes[0]
+	if len(n.contentTypes) > 1 {
+		ctx.w.Header().Add("Vary", "Accept")
+		if contentType = negotiateAccept(ctx.r.Header.Get("Accept"), n.contentTypes); len(contentType) == 0 {
+			ctx.ReplyError(http.StatusNotAcceptable, errors.New(http.StatusText(http.StatusNotAcceptable)))
+			return
+		}
+	}
+	serializer := Serializers[contentType]
+	if ctx.host != nil {
+		serializer = ctx.host.Serializer(contentType)
+	}
+	if serializer != nil {
+		ctx.Serializer = serializer
+	}
+	next(ctx)
+}
+
+//negotiateAccept select the content type with the highest quality
+func negotiateAccept(accept string, contentTypes []string) string {
+	if len(strings.TrimSpace(accept)) == 0 {
+		return contentTypes[0]
+	}
+	var selected string
+	var quality float64
+	for _, item := range strings.Split(accept, ",") {
+		mediaType := ParseMediaType(strings.TrimSpace(item))
+		q := mediaType.Quality()
+		if q <= quality {
+			continue
+		}
+		for _, contentType := range contentTypes {
+			if mediaType.Matches(contentType) {
+				selected, quality = contentType, q
+				break
+			}
+		}
+	}
+	return selected
+}
+
+func (*jsonAPISerializer) Marshal(obj interface{}) ([]byte, error) {
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if !value.IsValid() {
+		return json.Marshal(jsonAPIDocument{})
+	}
+	doc := jsonAPIDocument{}
+	included := map[string]*jsonAPIResource{}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		resources := make([]*jsonAPIResource, 0, value.Len())
+		for index := 0; index < value.Len(); index++ {
+			resource, err := jsonAPIResourceOf(value.Index(index), included)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, resource)
+		}
+		doc.Data = resources
+		for _, resource := range resources {
+			//the primary resources should not be included
+			delete(included, resource.Type+"/"+resource.ID)
+		}
+		if linker, isLinker := obj.(Linker); isLinker {
+			doc.Links = linker.Links()
+		}
+	case reflect.Struct:
+		resource, err := jsonAPIResourceOf(value, included)
+		if err != nil {
+			return nil, err
+		}
+		doc.Data = resource
+		//the primary resource should not be included
+		delete(included, resource.Type+"/"+resource.ID)
+	default:
+		return json.Marshal(obj)
+	}
+	keys := make([]string, 0, len(included))
+	for key := range included {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		doc.Included = append(doc.Included, included[key])
+	}
+	return json.Marshal(doc)
+}
+
+//Unmarshal The id and attributes of resource will be set into structure (relationships are ignored)
+func (*jsonAPISerializer) Unmarshal(src []byte, obj interface{}) error {
+	var doc struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(src, &doc); err != nil {
+		return err
+	}
+	typ := reflect.TypeOf(obj)
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+	flatten := func(resource *jsonAPIResource) map[string]interface{} {
+		object := resource.Attributes
+		if object == nil {
+			object = map[string]interface{}{}
+		}
+		if name, kind := resourceIDField(typ); len(name) > 0 && len(resource.ID) > 0 {
+			object[name] = resource.ID
+			if _, err := strconv.ParseFloat(resource.ID, 64); err == nil && kind != reflect.String {
+				object[name] = json.RawMessage(resource.ID)
+			}
+		}
+		return object
+	}
+	var data interface{}
+	if trimmed := strings.TrimSpace(string(doc.Data)); strings.HasPrefix(trimmed, "[") {
+		var resources []*jsonAPIResource
+		if err := json.Unmarshal(doc.Data, &resources); err != nil {
+			return err
+		}
+		objects := make([]map[string]interface{}, len(resources))
+		for index, resource := range resources {
+			objects[index] = flatten(resource)
+		}
+		data = objects
+	} else {
+		resource := &jsonAPIResource{}
+		if err := json.Unmarshal(doc.Data, resource); err != nil {
+			return err
+		}
+		data = flatten(resource)
+	}
+	flat, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(flat, obj)
+}
+
+func (*jsonAPISerializer) ContentType() string {
+	return "application/vnd.api+json"
+}
+
+func (*halSerializer) Marshal(obj interface{}) ([]byte, error) {
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if !value.IsValid() {
+		return json.Marshal(obj)
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		name, items := "items", make([]interface{}, 0, value.Len())
+		for index := 0; index < value.Len(); index++ {
+			item, err := halResourceOf(value.Index(index))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		if elem := indirectType(value.Type().Elem()); elem.Kind() == reflect.Struct {
+			name = resourceType(reflect.New(elem).Elem())
+		}
+		collection := map[string]interface{}{
+			"_embedded": map[string]interface{}{name: items},
+		}
+		if linker, isLinker := obj.(Linker); isLinker {
+			collection["_links"] = halLinks(linker.Links())
+		}
+		return json.Marshal(collection)
+	case reflect.Struct:
+		resource, err := halResourceOf(value)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(resource)
+	}
+	return json.Marshal(obj)
+}
+
+//Unmarshal The _links will be ignored and _embedded resources will be merged into object
+func (*halSerializer) Unmarshal(src []byte, obj interface{}) error {
+	var data interface{}
+	if err := json.Unmarshal(src, &data); err != nil {
+		return err
+	}
+	flat, err := json.Marshal(halFlatten(data))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(flat, obj)
+}
+
+func (*halSerializer) ContentType() string {
+	return "application/hal+json"
+}
+
+//jsonAPIResourceOf create resource object and collect the included resources
+func jsonAPIResourceOf(value reflect.Value, included map[string]*jsonAPIResource) (*jsonAPIResource, error) {
+	meta, err := resourceOf(value)
+	if err != nil {
+		return nil, err
+	}
+	resource := &jsonAPIResource{
+		Type:       meta.typ,
+		ID:         meta.id,
+		Attributes: meta.attributes,
+	}
+	if linker, isLinker := meta.value.Interface().(Linker); isLinker {
+		resource.Links = linker.Links()
+	} else if meta.value.CanAddr() {
+		if linker, isLinker := meta.value.Addr().Interface().(Linker); isLinker {
+			resource.Links = linker.Links()
+		}
+	}
+	key := resource.Type + "/" + resource.ID
+	if _, existed := included[key]; !existed {
+		//reserve the key first to stop recursion
+		included[key] = resource
+	}
+	for name, relation := range meta.relations {
+		if len(resource.Relationships) == 0 {
+			resource.Relationships = map[string]jsonAPIRelationship{}
+		}
+		relation = reflect.Indirect(relation)
+		switch {
+		case !relation.IsValid():
+			resource.Relationships[name] = jsonAPIRelationship{}
+		case relation.Kind() == reflect.Slice || relation.Kind() == reflect.Array:
+			identifiers := make([]jsonAPIIdentifier, 0, relation.Len())
+			for index := 0; index < relation.Len(); index++ {
+				identifier, err := jsonAPIInclude(relation.Index(index), included)
+				if err != nil {
+					return nil, err
+				}
+				identifiers = append(identifiers, identifier)
+			}
+			resource.Relationships[name] = jsonAPIRelationship{Data: identifiers}
+		default:
+			identifier, err := jsonAPIInclude(relation, included)
+			if err != nil {
+				return nil, err
+			}
+			resource.Relationships[name] = jsonAPIRelationship{Data: identifier}
+		}
+	}
+	return resource, nil
+}
+
+//jsonAPIInclude include the related resource and return its identifier
+func jsonAPIInclude(value reflect.Value, included map[string]*jsonAPIResource) (jsonAPIIdentifier, error) {
+	meta, err := resourceOf(value)
+	if err != nil {
+		return jsonAPIIdentifier{}, err
+	}
+	if _, existed := included[meta.typ+"/"+meta.id]; !existed {
+		if _, err = jsonAPIResourceOf(value, included); err != nil {
+			return jsonAPIIdentifier{}, err
+		}
+	}
+	return jsonAPIIdentifier{Type: meta.typ, ID: meta.id}, nil
+}
+
+//halResourceOf create HAL resource with _links and _embedded
+func halResourceOf(value reflect.Value) (map[string]interface{}, error) {
+	meta, err := resourceOf(value)
+	if err != nil {
+		return nil, err
+	}
+	resource := meta.attributes
+	if resource == nil {
+		resource = map[string]interface{}{}
+	}
+	if name, _ := resourceIDField(meta.value.Type()); len(name) > 0 {
+		resource[name] = meta.value.FieldByIndex(resourceIDIndex(meta.value.Type())).Interface()
+	}
+	if linker, isLinker := meta.value.Interface().(Linker); isLinker {
+		resource["_links"] = halLinks(linker.Links())
+	} else if meta.value.CanAddr() {
+		if linker, isLinker := meta.value.Addr().Interface().(Linker); isLinker {
+			resource["_links"] = halLinks(linker.Links())
+		}
+	}
+	embedded := map[string]interface{}{}
+	for name, relation := range meta.relations {
+		relation = reflect.Indirect(relation)
+		switch {
+		case !relation.IsValid():
+			continue
+		case relation.Kind() == reflect.Slice || relation.Kind() == reflect.Array:
+			items := make([]interface{}, 0, relation.Len())
+			for index := 0; index < relation.Len(); index++ {
+				item, err := halResourceOf(relation.Index(index))
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			embedded[name] = items
+		default:
+			item, err := halResourceOf(relation)
+			if err != nil {
+				return nil, err
+			}
+			embedded[name] = item
+		}
+	}
+	if len(embedded) > 0 {
+		resource["_embedded"] = embedded
+	}
+	return resource, nil
+}
+
+func halLinks(links map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(links))
+	for rel, href := range links {
+		result[rel] = map[string]string{"href": href}
+	}
+	return result
+}
+
+//halFlatten remove _links and merge _embedded
+func halFlatten(data interface{}) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		delete(value, "_links")
+		if embedded, isMap := value["_embedded"].(map[string]interface{}); isMap {
+			delete(value, "_embedded")
+			for name, item := range embedded {
+				value[name] = item
+			}
+		}
+		for name, item := range value {
+			value[name] = halFlatten(item)
+		}
+	case []interface{}:
+		for index, item := range value {
+			value[index] = halFlatten(item)
+		}
+	}
+	return data
+}
+
+//resourceOf collect the metadata of structure as resource
+func resourceOf(value reflect.Value) (*resourceMeta, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, errors.New("nil resource")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, errors.New("type " + value.Type().String() + " is not a resource")
+	}
+	meta := &resourceMeta{
+		value:     value,
+		typ:       resourceType(value),
+		relations: map[string]reflect.Value{},
+	}
+	if index := resourceIDIndex(value.Type()); index != nil {
+		meta.id = fmt.Sprint(value.FieldByIndex(index).Interface())
+	}
+	meta.collect(value)
+	return meta, nil
+}
+
+//collect the attributes and relations with the rules of encoding/json
+func (meta *resourceMeta) collect(value reflect.Value) {
+	typ := value.Type()
+	for index := 0; index < typ.NumField(); index++ {
+		field := typ.Field(index)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		resourceTag := field.Tag.Get("resource")
+		if tag[0] == "-" || resourceTag == "-" {
+			continue
+		}
+		fieldValue := value.Field(index)
+		if field.Anonymous && len(tag[0]) == 0 && indirectType(field.Type).Kind() == reflect.Struct {
+			if fieldValue = reflect.Indirect(fieldValue); fieldValue.IsValid() {
+				meta.collect(fieldValue)
+			}
+			continue
+		}
+		if len(field.PkgPath) > 0 || resourceTag == "id" || (len(resourceTag) == 0 && field.Name == "ID") {
+			continue
+		}
+		name := tag[0]
+		if len(name) == 0 {
+			name = field.Name
+		}
+		if resourceTag == "rel" {
+			meta.relations[name] = fieldValue
+			continue
+		}
+		if len(tag) > 1 && tag[1] == "omitempty" && isEmptyValue(fieldValue) {
+			continue
+		}
+		if meta.attributes == nil {
+			meta.attributes = map[string]interface{}{}
+		}
+		meta.attributes[name] = fieldValue.Interface()
+	}
+}
+
+//resourceType get type from ResourceTyper or the name of structure
+func resourceType(value reflect.Value) string {
+	if typer, isTyper := value.Interface().(ResourceTyper); isTyper {
+		return typer.ResourceType()
+	}
+	if value.CanAddr() {
+		if typer, isTyper := value.Addr().Interface().(ResourceTyper); isTyper {
+			return typer.ResourceType()
+		}
+	}
+	if typer, isTyper := reflect.New(value.Type()).Interface().(ResourceTyper); isTyper {
+		return typer.ResourceType()
+	}
+	return strings.ToLower(value.Type().Name())
+}
+
+//resourceIDIndex find the field with `resource:"id"` or named ID
+func resourceIDIndex(typ reflect.Type) []int {
+	var fallback []int
+	for index := 0; index < typ.NumField(); index++ {
+		field := typ.Field(index)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		if field.Tag.Get("resource") == "id" {
+			return field.Index
+		}
+		if field.Name == "ID" && len(field.Tag.Get("resource")) == 0 {
+			fallback = field.Index
+		}
+	}
+	return fallback
+}
+
+//resourceIDField the json name and kind of id field
+func resourceIDField(typ reflect.Type) (string, reflect.Kind) {
+	if typ.Kind() != reflect.Struct {
+		return "", reflect.Invalid
+	}
+	index := resourceIDIndex(typ)
+	if index == nil {
+		return "", reflect.Invalid
+	}
+	field := typ.FieldByIndex(index)
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if len(name) == 0 || name == "-" {
+		name = field.Name
+	}
+	return name, indirectType(field.Type).Kind()
+}
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+//isEmptyValue the same as omitempty of encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -1,16 +1,22 @@
 package webapi
 
 import (
+	"bufio"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strconv"
 )
 
-//JSONSerializer JSON Serializer
+//ErrBodyTooLarge The request body exceeds the limitation of host
+var ErrBodyTooLarge = errors.New("http: request body too large")
+
+//Serializers Global serializers, the serializers registered in host will take precedence over them
 var (
 	Serializers = map[string]Serializer{
 		"application/x-www-form-urlencoded": &formSerializer{},
@@ -27,16 +33,35 @@ type (
 	responsewriter struct {
 		ctx *Context
 	}
+
+	//limitedBody the body will return ErrBodyTooLarge if the limitation is exceeded
+	limitedBody struct {
+		io.ReadCloser
+		remaining int64
+	}
+
+	//newlineTrimmer the writer trims the trailing newline written by json encoder
+	newlineTrimmer struct {
+		io.Writer
+	}
 )
 
 func (*xmlSerializer) Marshal(obj interface{}) ([]byte, error) {
 	return xml.Marshal(obj)
 }
 
+func (*xmlSerializer) MarshalTo(w io.Writer, obj interface{}) error {
+	return xml.NewEncoder(w).Encode(obj)
+}
+
 func (*xmlSerializer) Unmarshal(src []byte, obj interface{}) error {
 	return xml.Unmarshal(src, obj)
 }
 
+func (*xmlSerializer) UnmarshalStream(r io.Reader, obj interface{}) error {
+	return xml.NewDecoder(r).Decode(obj)
+}
+
 func (*xmlSerializer) ContentType() string {
 	return "application/xml; charset=utf-8"
 }
@@ -45,10 +70,33 @@ func (*jsonSerializer) Marshal(obj interface{}) ([]byte, error) {
 	return json.Marshal(obj)
 }
 
+func (*jsonSerializer) MarshalTo(w io.Writer, obj interface{}) error {
+	return json.NewEncoder(&newlineTrimmer{w}).Encode(obj)
+}
+
 func (*jsonSerializer) Unmarshal(src []byte, obj interface{}) error {
 	return json.Unmarshal(src, obj)
 }
 
+func (*jsonSerializer) UnmarshalStream(r io.Reader, obj interface{}) error {
+	decoder := json.NewDecoder(r)
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	//the same as Unmarshal, nothing but spaces is allowed after the value
+	rest, char := bufio.NewReader(io.MultiReader(decoder.Buffered(), r)), byte(0)
+	var err error
+	for char, err = rest.ReadByte(); err == nil; char, err = rest.ReadByte() {
+		if char != ' ' && char != '\t' && char != '\r' && char != '\n' {
+			return fmt.Errorf("invalid character %s after top-level value", strconv.QuoteRune(rune(char)))
+		}
+	}
+	if err != io.EOF {
+		return err
+	}
+	return nil
+}
+
 func (*jsonSerializer) ContentType() string {
 	return "application/json; charset=utf-8"
 }
@@ -111,12 +159,79 @@ func (reply Reply) Data() interface{} {
 	return reply.Body
 }
 
+//HTTPError Error with HTTP status code
+type HTTPError struct {
+	Status int
+	Err    error
+}
+
+//NewHTTPError Create error with HTTP status code
+func NewHTTPError(httpstatus int, err ...error) *HTTPError {
+	if len(err) == 0 || err[0] == nil {
+		err = []error{errors.New(http.StatusText(httpstatus))}
+	}
+	return &HTTPError{
+		Status: httpstatus,
+		Err:    err[0],
+	}
+}
+
+func (err *HTTPError) Error() string {
+	return err.Err.Error()
+}
+
+//Unwrap Return the original error
+func (err *HTTPError) Unwrap() error {
+	return err.Err
+}
+
+//StatusCode HTTP Status Code
+func (err *HTTPError) StatusCode() int {
+	return err.Status
+}
+
+//errorStatus get the status code from error (e.g. HTTPError), the fallback status will be returned if there is no status code
+func errorStatus(err error, fallback int) int {
+	var coder interface{ StatusCode() int }
+	if errors.As(err, &coder) && coder.StatusCode() > 0 {
+		return coder.StatusCode()
+	}
+	return fallback
+}
+
+func (body *limitedBody) Read(p []byte) (n int, err error) {
+	if body.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	if int64(len(p)) > body.remaining+1 {
+		//read one more byte to detect the overflow
+		p = p[:body.remaining+1]
+	}
+	n, err = body.ReadCloser.Read(p)
+	if int64(n) > body.remaining {
+		n, err = int(body.remaining), ErrBodyTooLarge
+		body.remaining = -1
+		return
+	}
+	body.remaining -= int64(n)
+	return
+}
+
+//Write the encoder writes each value with the trailing newline at once
+func (w *newlineTrimmer) Write(p []byte) (int, error) {
+	if n := len(p); n > 0 && p[n-1] == '\n' {
+		_, err := w.Writer.Write(p[:n-1])
+		return n, err
+	}
+	return w.Writer.Write(p)
+}
+
 func (w *responsewriter) Write(p []byte) (int, error) {
-	defer func() {
-		if w.ctx.statuscode == 0 {
-			w.ctx.statuscode = 200 //mark data has been transferred
-		}
-	}()
+	if w.ctx.statuscode == 0 {
+		//mark data has been transferred
+		w.ctx.statuscode = http.StatusOK
+		w.ctx.writeHeader(http.StatusOK)
+	}
 	return w.ctx.w.Write(p)
 }
 
@@ -126,5 +241,5 @@ func (w *responsewriter) Header() http.Header {
 
 func (w *responsewriter) WriteHeader(statusCode int) {
 	w.ctx.statuscode = statusCode
-	w.ctx.w.WriteHeader(statusCode)
+	w.ctx.writeHeader(statusCode)
 }
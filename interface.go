@@ -1,6 +1,7 @@
 package webapi
 
 import (
+	"io"
 	"net/http"
 )
 
@@ -42,6 +43,23 @@ type (
 		ContentType() string
 	}
 
+	//StreamDeserializer The serializer which is able to deserialize from reader directly, it is used to bind the body
+	//without buffering when no BeforeReading is installed and the body has not been read
+	StreamDeserializer interface {
+		UnmarshalStream(io.Reader, interface{}) error
+	}
+
+	//StreamSerializer The serializer which is able to marshal into writer directly, it is used by Reply to marshal
+	//into the pooled buffer (the output should be the same as Marshal)
+	StreamSerializer interface {
+		MarshalTo(io.Writer, interface{}) error
+	}
+
+	//StateReporter Middleware which reports its runtime state (e.g. rate limit) to admin endpoints
+	StateReporter interface {
+		State() interface{}
+	}
+
 	//LogService Log service
 	LogService interface {
 		//Log with [datetime] prefix
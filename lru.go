@@ -0,0 +1,73 @@
+package webapi
+
+import (
+	"container/list"
+	"sync"
+)
+
+type (
+	//lru the least recently used cache (concurrency safe)
+	lru struct {
+		mutex    sync.Mutex
+		capacity int
+		items    map[string]*list.Element
+		order    *list.List
+	}
+
+	lruEntry struct {
+		key   string
+		value interface{}
+	}
+)
+
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		items:    map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+//Get Get the value and mark it as recently used
+func (cache *lru) Get(key string) (interface{}, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if element, existed := cache.items[key]; existed {
+		cache.order.MoveToFront(element)
+		return element.Value.(*lruEntry).value, true
+	}
+	return nil, false
+}
+
+//Add Add the value, the least recently used one will be evicted if the capacity is exceeded
+func (cache *lru) Add(key string, value interface{}) {
+	if cache.capacity <= 0 {
+		return
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if element, existed := cache.items[key]; existed {
+		cache.order.MoveToFront(element)
+		element.Value.(*lruEntry).value = value
+		return
+	}
+	if cache.order.Len() >= cache.capacity {
+		//reuse the evicted element
+		element := cache.order.Back()
+		entry := element.Value.(*lruEntry)
+		delete(cache.items, entry.key)
+		entry.key, entry.value = key, value
+		cache.items[key] = element
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.items[key] = cache.order.PushFront(&lruEntry{key: key, value: value})
+}
+
+//Purge Remove all values
+func (cache *lru) Purge() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.items = map[string]*list.Element{}
+	cache.order.Init()
+}
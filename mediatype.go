@@ -0,0 +1,76 @@
+package webapi
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+//mediaTypesCapacity the capacity of parsed media types cache
+const mediaTypesCapacity = 256
+
+type (
+	//MediaType The parsed media type of Content-Type (or the item of Accept), the names and values are lowered
+	//except the values of Params. It is shared by cache and must not be modified.
+	MediaType struct {
+		//Type The type and subtype without parameters, e.g. application/json
+		Type string
+		//Charset The charset parameter (empty if not declared)
+		Charset string
+		//Params The parameters except charset (nil if none)
+		Params map[string]string
+	}
+)
+
+var (
+	//mediaTypes the cache of parsed media types by raw value
+	mediaTypes = newLRU(mediaTypesCapacity)
+)
+
+//ParseMediaType Parse the media type (e.g. "application/json; charset=UTF-8"), the results are cached,
+//the malformed parameters are ignored
+func ParseMediaType(value string) MediaType {
+	if len(value) == 0 {
+		return MediaType{}
+	}
+	if cached, existed := mediaTypes.Get(value); existed {
+		return cached.(MediaType)
+	}
+	var mediaType MediaType
+	if typ, params, err := mime.ParseMediaType(value); err == nil {
+		mediaType.Type, mediaType.Charset = typ, strings.ToLower(params["charset"])
+		delete(params, "charset")
+		if len(params) > 0 {
+			mediaType.Params = params
+		}
+	} else {
+		mediaType.Type = strings.ToLower(strings.TrimSpace(strings.Split(value, ";")[0]))
+	}
+	mediaTypes.Add(value, mediaType)
+	return mediaType
+}
+
+//Quality The q parameter of Accept item (1 if not declared)
+func (mediaType MediaType) Quality() float64 {
+	if q, existed := mediaType.Params["q"]; existed {
+		quality, _ := strconv.ParseFloat(q, 64)
+		return quality
+	}
+	return 1
+}
+
+//Matches Whether the media type (which might be wildcard, e.g. text/* or */*) accepts the content type
+func (mediaType MediaType) Matches(contentType string) bool {
+	switch {
+	case mediaType.Type == contentType || mediaType.Type == "*/*":
+		return true
+	case strings.HasSuffix(mediaType.Type, "/*"):
+		return strings.HasPrefix(contentType, mediaType.Type[:len(mediaType.Type)-1])
+	}
+	return false
+}
+
+//MediaType The parsed Content-Type of request
+func (ctx *Context) MediaType() MediaType {
+	return ctx.mediaType
+}
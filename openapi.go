@@ -0,0 +1,360 @@
+package webapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	//OpenAPIDocument OpenAPI 3 document
+	OpenAPIDocument struct {
+		OpenAPI    string                                  `json:"openapi"`
+		Info       OpenAPIInfo                             `json:"info"`
+		Servers    []OpenAPIServer                         `json:"servers,omitempty"`
+		Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+		Components OpenAPIComponents                       `json:"components"`
+	}
+
+	//OpenAPIInfo Metadata about the API
+	OpenAPIInfo struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	//OpenAPIServer Server of the API
+	OpenAPIServer struct {
+		URL         string `json:"url"`
+		Description string `json:"description,omitempty"`
+	}
+
+	//OpenAPIComponents Reusable objects (only schemas are generated)
+	OpenAPIComponents struct {
+		Schemas map[string]*OpenAPISchema `json:"schemas,omitempty"`
+	}
+
+	//OpenAPIOperation API operation of path
+	OpenAPIOperation struct {
+		OperationID string                      `json:"operationId,omitempty"`
+		Summary     string                      `json:"summary,omitempty"`
+		Tags        []string                    `json:"tags,omitempty"`
+		Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+		RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*OpenAPIResponse `json:"responses"`
+	}
+
+	//OpenAPIParameter Parameter from path or query
+	OpenAPIParameter struct {
+		Name        string         `json:"name"`
+		In          string         `json:"in"`
+		Description string         `json:"description,omitempty"`
+		Required    bool           `json:"required,omitempty"`
+		Schema      *OpenAPISchema `json:"schema"`
+	}
+
+	//OpenAPIRequestBody Request body
+	OpenAPIRequestBody struct {
+		Required bool                        `json:"required,omitempty"`
+		Content  map[string]OpenAPIMediaType `json:"content"`
+	}
+
+	//OpenAPIResponse Response of operation
+	OpenAPIResponse struct {
+		Description string                      `json:"description"`
+		Headers     map[string]*OpenAPIHeader   `json:"headers,omitempty"`
+		Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+	}
+
+	//OpenAPIHeader Header of response
+	OpenAPIHeader struct {
+		Description string         `json:"description,omitempty"`
+		Schema      *OpenAPISchema `json:"schema"`
+	}
+
+	//OpenAPIMediaType Schema of content type
+	OpenAPIMediaType struct {
+		Schema *OpenAPISchema `json:"schema"`
+	}
+
+	//OpenAPISchema Schema object (subset of JSON Schema)
+	OpenAPISchema struct {
+		Ref                  string                    `json:"$ref,omitempty"`
+		AllOf                []*OpenAPISchema          `json:"allOf,omitempty"`
+		Type                 string                    `json:"type,omitempty"`
+		Format               string                    `json:"format,omitempty"`
+		Description          string                    `json:"description,omitempty"`
+		Items                *OpenAPISchema            `json:"items,omitempty"`
+		Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+		AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+		Example              interface{}               `json:"example,omitempty"`
+	}
+
+	//schemaBuilder build schemas and collect named structures into components
+	schemaBuilder struct {
+		schemas map[string]*OpenAPISchema
+		names   map[reflect.Type]string
+	}
+)
+
+var (
+	//placeholderSchemas schema of path placeholders
+	placeholderSchemas = map[string]func() *OpenAPISchema{
+		"{digits}": func() *OpenAPISchema { return &OpenAPISchema{Type: "integer"} },
+		"{float}":  func() *OpenAPISchema { return &OpenAPISchema{Type: "number"} },
+		"{bool}":   func() *OpenAPISchema { return &OpenAPISchema{Type: "boolean"} },
+		"{string}": func() *OpenAPISchema { return &OpenAPISchema{Type: "string"} },
+	}
+
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+//OpenAPI Generate OpenAPI 3 document from the registered routes (tenant routes are excluded).
+//The "description" and "example" tags of structure fields will be used to enrich the schemas.
+func (host *Host) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	host.initCheck()
+	if len(info.Version) == 0 {
+		info.Version = "1.0.0"
+	}
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   map[string]map[string]*OpenAPIOperation{},
+	}
+	builder := &schemaBuilder{
+		schemas: map[string]*OpenAPISchema{},
+		names:   map[reflect.Type]string{},
+	}
+	contentType := host.conf.DefaultContentType
+	for _, route := range host.registeredRoutes() {
+		if len(route.Tenant) > 0 {
+			continue
+		}
+		path, params := openAPIPath(route)
+		operations, existed := doc.Paths[path]
+		if !existed {
+			operations = map[string]*OpenAPIOperation{}
+			doc.Paths[path] = operations
+		}
+		method := strings.ToLower(route.Method)
+		if _, existed := operations[method]; existed {
+			//e.g. /{digits} and /{string} are the same path in OpenAPI, the first one will be kept
+			continue
+		}
+		operation := &OpenAPIOperation{
+			Parameters: params,
+			Responses:  builder.responses(route.Return, contentType),
+		}
+		if len(route.Controller) > 0 {
+			operation.Tags = []string{route.Controller}
+			operation.OperationID = route.Controller + "_" + route.Action + "_" + method
+		}
+		for index, param := range operation.Parameters {
+			if index < len(route.Params) {
+				param.Schema = builder.schema(route.Params[index])
+			}
+		}
+		if route.Query != nil {
+			for _, field := range queryFields(route.Query) {
+				operation.Parameters = append(operation.Parameters, &OpenAPIParameter{
+					Name:        field.Name,
+					In:          "query",
+					Description: field.Tag.Get("description"),
+					Schema:      builder.schema(field.Type),
+				})
+			}
+			if hasPage(route.Query) {
+				builder.page(operation.Responses, route.Return, contentType)
+			}
+		}
+		if route.Body != nil {
+			operation.RequestBody = &OpenAPIRequestBody{
+				Content: map[string]OpenAPIMediaType{
+					contentType: {Schema: builder.schema(route.Body)},
+				},
+			}
+		}
+		operations[method] = operation
+	}
+	doc.Components.Schemas = builder.schemas
+	return doc
+}
+
+//ExportOpenAPI Write the OpenAPI 3 document as JSON
+func (host *Host) ExportOpenAPI(w io.Writer, info OpenAPIInfo) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(host.OpenAPI(info))
+}
+
+//ServeOpenAPI Register the endpoint to serve OpenAPI 3 document, default path is "/openapi.json"
+func (host *Host) ServeOpenAPI(path string, info OpenAPIInfo, middlewares ...Middleware) error {
+	if len(path) == 0 {
+		path = "/openapi.json"
+	}
+	return host.AddEndpoint(http.MethodGet, path, func(ctx *Context) {
+		replyJSON(ctx, http.StatusOK, host.OpenAPI(info))
+	}, middlewares...)
+}
+
+//openAPIPath replace placeholders with named parameters
+func openAPIPath(route RouteInfo) (string, []*OpenAPIParameter) {
+	var params []*OpenAPIParameter
+	segments := strings.Split(route.Path, "/")
+	for index, segment := range segments {
+		if schema, isPlaceholder := placeholderSchemas[segment]; isPlaceholder {
+			name := "param" + strconv.Itoa(len(params)+1)
+			params = append(params, &OpenAPIParameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   schema(),
+			})
+			segments[index] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+//responses create responses from the type of return value
+func (builder *schemaBuilder) responses(typ reflect.Type, contentType string) map[string]*OpenAPIResponse {
+	response := &OpenAPIResponse{Description: http.StatusText(http.StatusOK)}
+	if typ != nil && typ.Kind() != reflect.Interface {
+		elem := typ
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if !marshalableKinds[elem.Kind()] || (elem.Kind() == reflect.Slice && elem.Elem().Kind() == reflect.Uint8) {
+			//replied as text directly
+			contentType = "text/plain"
+		}
+		response.Content = map[string]OpenAPIMediaType{contentType: {Schema: builder.schema(typ)}}
+	}
+	return map[string]*OpenAPIResponse{
+		strconv.Itoa(http.StatusOK): response,
+	}
+}
+
+//page document the Link header of ReplyPage, and the envelope if the action replies by itself
+func (builder *schemaBuilder) page(responses map[string]*OpenAPIResponse, typ reflect.Type, contentType string) {
+	response := responses[strconv.Itoa(http.StatusOK)]
+	response.Headers = map[string]*OpenAPIHeader{
+		"Link": {Description: "The links of first, prev, next and last pages (RFC 5988)", Schema: &OpenAPISchema{Type: "string"}},
+	}
+	if typ == nil {
+		response.Content = map[string]OpenAPIMediaType{contentType: {Schema: builder.schema(reflect.TypeOf(PageResult{}))}}
+	}
+}
+
+//schema create schema from type, named structures will be referenced from components
+func (builder *schemaBuilder) schema(typ reflect.Type) *OpenAPISchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		return &OpenAPISchema{Type: "string", Format: "byte"}
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int64, reflect.Uint64, reflect.Int, reflect.Uint:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &OpenAPISchema{Type: "array", Items: builder.schema(typ.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: builder.schema(typ.Elem())}
+	case reflect.Struct:
+		if len(typ.Name()) == 0 {
+			//anonymous structure will be inline
+			return builder.object(typ)
+		}
+		name, existed := builder.names[typ]
+		if !existed {
+			name = typ.Name()
+			for index := 2; builder.schemas[name] != nil; index++ {
+				name = typ.Name() + strconv.Itoa(index)
+			}
+			builder.names[typ] = name
+			//reserve the name first for recursive structures
+			builder.schemas[name] = &OpenAPISchema{}
+			*builder.schemas[name] = *builder.object(typ)
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+	}
+	return &OpenAPISchema{}
+}
+
+//object create object schema from structure with the rules of encoding/json
+func (builder *schemaBuilder) object(typ reflect.Type) *OpenAPISchema {
+	schema := &OpenAPISchema{
+		Type:       "object",
+		Properties: map[string]*OpenAPISchema{},
+	}
+	for index := 0; index < typ.NumField(); index++ {
+		field := typ.Field(index)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && len(tag[0]) == 0 && fieldType.Kind() == reflect.Struct {
+			//embedded structure will be flattened
+			for name, property := range builder.object(fieldType).Properties {
+				if _, existed := schema.Properties[name]; !existed {
+					schema.Properties[name] = property
+				}
+			}
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		name := tag[0]
+		if len(name) == 0 {
+			name = field.Name
+		}
+		property := builder.schema(field.Type)
+		if description, example := field.Tag.Get("description"), field.Tag.Get("example"); len(description) > 0 || len(example) > 0 {
+			if len(property.Ref) > 0 {
+				//siblings of $ref are ignored, wrap it with allOf
+				property = &OpenAPISchema{AllOf: []*OpenAPISchema{property}}
+			}
+			property.Description = description
+			if len(example) > 0 {
+				property.Example = exampleValue(property.Type, example)
+			}
+		}
+		schema.Properties[name] = property
+	}
+	return schema
+}
+
+//exampleValue convert the example text to the value of schema type
+func exampleValue(typ string, example string) interface{} {
+	if typ == "string" || len(typ) == 0 {
+		return example
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(example), &value); err != nil {
+		return example
+	}
+	return value
+}
@@ -0,0 +1,153 @@
+package webapi
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	//DefaultPageLimit The limit of page if it is not specified
+	DefaultPageLimit = 20
+	//MaxPageLimit The maximum limit of page, the larger one will be capped
+	MaxPageLimit = 100
+	//MaxPageNumber The maximum page number, the offset of it cannot overflow int on any platform (400 if exceeded)
+	MaxPageNumber = math.MaxInt32 / MaxPageLimit
+)
+
+type (
+	//Page The standard pagination query, it could be the query parameter or embedded into the query structure
+	//(Check applies the defaults and caps, call it manually if the query structure has its own Check).
+	//The cursor is preferred if it is provided by client, set Next to the cursor of next page before ReplyPage.
+	Page struct {
+		Page   int    `json:"page" description:"The page number started from 1 (default 1)"`
+		Limit  int    `json:"limit" description:"The maximum items of page (default 20, at most 100)"`
+		Cursor string `json:"cursor" description:"The opaque cursor of page, it is preferred to page number"`
+		//Next The cursor of next page (empty if there is no more items)
+		Next string `json:"-"`
+	}
+
+	//PageResult The envelope replied by ReplyPage
+	PageResult struct {
+		Items interface{} `json:"items"`
+		//Total The total of items (negative if unknown)
+		Total  int64  `json:"total"`
+		Page   int    `json:"page,omitempty"`
+		Limit  int    `json:"limit"`
+		Cursor string `json:"cursor,omitempty"`
+		Next   string `json:"next,omitempty"`
+	}
+)
+
+var pageType = reflect.TypeOf(Page{})
+
+//Check Apply the defaults and caps, the error with 400 Bad Request is returned if the page number exceeds MaxPageNumber
+func (page *Page) Check() error {
+	if page.Page < 1 {
+		page.Page = 1
+	} else if page.Page > MaxPageNumber {
+		return NewHTTPError(http.StatusBadRequest, errors.New("the page number cannot exceed "+strconv.Itoa(MaxPageNumber)))
+	}
+	if page.Limit <= 0 {
+		page.Limit = DefaultPageLimit
+	} else if page.Limit > MaxPageLimit {
+		page.Limit = MaxPageLimit
+	}
+	return nil
+}
+
+//Offset The count of items before the page (zero for cursor pagination)
+func (page *Page) Offset() int {
+	if len(page.Cursor) > 0 {
+		return 0
+	}
+	return (page.Page - 1) * page.Limit
+}
+
+//ReplyPage Reply the items with the envelope (PageResult) and the Link header (RFC 5988) of first, prev, next and last pages,
+//the links keep the other queries of request. Total is the count of all items (negative if unknown),
+//the next link is given if there are more items by total (or Next cursor, or a full page when total is unknown).
+func (ctx *Context) ReplyPage(items interface{}, total int64, page *Page) error {
+	if page == nil {
+		page = &Page{}
+	}
+	if err := page.Check(); err != nil {
+		return err
+	}
+	result := &PageResult{Items: items, Total: total, Limit: page.Limit, Cursor: page.Cursor, Next: page.Next}
+	if links := ctx.pageLinks(items, total, page); len(links) > 0 {
+		ctx.w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	if len(page.Cursor) == 0 && len(page.Next) == 0 {
+		result.Page = page.Page
+	}
+	return ctx.Reply(http.StatusOK, result)
+}
+
+//pageLinks the link values of pages
+func (ctx *Context) pageLinks(items interface{}, total int64, page *Page) []string {
+	link := func(rel string, cursor string, number int) string {
+		queries := ctx.r.URL.Query()
+		for _, name := range []string{"page", "limit", "cursor"} {
+			delete(queries, name)
+		}
+		queries.Set("limit", strconv.Itoa(page.Limit))
+		if len(cursor) > 0 {
+			queries.Set("cursor", cursor)
+		} else if number > 0 {
+			queries.Set("page", strconv.Itoa(number))
+		}
+		return "<" + ctx.r.URL.Path + "?" + queries.Encode() + ">; rel=\"" + rel + "\""
+	}
+	if len(page.Cursor) > 0 || len(page.Next) > 0 {
+		//the cursor cannot go backward
+		links := []string{link("first", "", 0)}
+		if len(page.Next) > 0 {
+			links = append(links, link("next", page.Next, 0))
+		}
+		return links
+	}
+	links := []string{link("first", "", 1)}
+	if page.Page > 1 {
+		links = append(links, link("prev", "", page.Page-1))
+	}
+	if total < 0 {
+		if value := reflect.Indirect(reflect.ValueOf(items)); (value.Kind() == reflect.Slice || value.Kind() == reflect.Array) && value.Len() >= page.Limit {
+			links = append(links, link("next", "", page.Page+1))
+		}
+		return links
+	}
+	last := (total + int64(page.Limit) - 1) / int64(page.Limit)
+	if last < 1 {
+		last = 1
+	} else if last > MaxPageNumber {
+		//the pages beyond cannot be requested
+		last = MaxPageNumber
+	}
+	if int64(page.Page) < last {
+		links = append(links, link("next", "", page.Page+1))
+	}
+	return append(links, link("last", "", int(last)))
+}
+
+//hasPage whether the query structure is (or contains) Page
+func hasPage(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == pageType {
+		return true
+	}
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	for index := 0; index < typ.NumField(); index++ {
+		if field := typ.Field(index); field.Type.Kind() == reflect.Struct && hasPage(field.Type) {
+			return true
+		}
+	}
+	return false
+}
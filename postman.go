@@ -0,0 +1,125 @@
+package webapi
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	postmanKeyValue struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+
+	postmanURL struct {
+		Raw      string            `json:"raw"`
+		Host     []string          `json:"host"`
+		Path     []string          `json:"path"`
+		Query    []postmanKeyValue `json:"query,omitempty"`
+		Variable []postmanKeyValue `json:"variable,omitempty"`
+	}
+
+	postmanBody struct {
+		Mode    string                 `json:"mode"`
+		Raw     string                 `json:"raw"`
+		Options map[string]interface{} `json:"options,omitempty"`
+	}
+
+	postmanRequest struct {
+		Method string            `json:"method"`
+		Header []postmanKeyValue `json:"header"`
+		URL    postmanURL        `json:"url"`
+		Body   *postmanBody      `json:"body,omitempty"`
+	}
+
+	postmanItem struct {
+		Name    string          `json:"name"`
+		Item    []*postmanItem  `json:"item,omitempty"`
+		Request *postmanRequest `json:"request,omitempty"`
+	}
+)
+
+//ExportPostman Write the Postman collection (v2.1, also accepted by Insomnia) of routes (tenant routes are excluded),
+//the requests are grouped into folders per controller and the base URL is the collection variable {{baseUrl}}
+func (host *Host) ExportPostman(w io.Writer) error {
+	var items []*postmanItem
+	folders := map[string]*postmanItem{}
+	for _, example := range host.exampleRequests() {
+		item := &postmanItem{
+			Name:    example.name(),
+			Request: example.postman(),
+		}
+		if len(example.route.Controller) == 0 {
+			items = append(items, item)
+			continue
+		}
+		if len(example.route.Action) > 0 {
+			item.Name = example.route.Action
+		}
+		folder, existed := folders[example.route.Controller]
+		if !existed {
+			folder = &postmanItem{Name: example.route.Controller, Item: []*postmanItem{}}
+			folders[example.route.Controller] = folder
+		}
+		folder.Item = append(folder.Item, item)
+	}
+	names := make([]string, 0, len(folders))
+	for name := range folders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collection := []*postmanItem{}
+	for _, name := range names {
+		collection = append(collection, folders[name])
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{
+		"info": map[string]string{
+			"name":   "webapi",
+			"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+		},
+		"item":     append(collection, items...),
+		"variable": []postmanKeyValue{{Key: "baseUrl", Value: "http://localhost"}},
+	})
+}
+
+//postman create the request of collection, path placeholders are replaced with variables
+func (example *exampleRequest) postman() *postmanRequest {
+	request := &postmanRequest{
+		Method: example.route.Method,
+		Header: []postmanKeyValue{},
+		URL:    postmanURL{Host: []string{"{{baseUrl}}"}},
+	}
+	for _, segment := range strings.Split(strings.TrimPrefix(example.route.Path, "/"), "/") {
+		if value, isPlaceholder := placeholderExamples[segment]; isPlaceholder {
+			name := "param" + strconv.Itoa(len(request.URL.Variable)+1)
+			request.URL.Variable = append(request.URL.Variable, postmanKeyValue{Key: name, Value: value})
+			segment = ":" + name
+		}
+		request.URL.Path = append(request.URL.Path, segment)
+	}
+	request.URL.Raw = "{{baseUrl}}/" + strings.Join(request.URL.Path, "/")
+	if len(example.query) > 0 {
+		names := make([]string, 0, len(example.query))
+		for name := range example.query {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			request.URL.Query = append(request.URL.Query, postmanKeyValue{Key: name, Value: example.query.Get(name)})
+		}
+		request.URL.Raw += "?" + example.query.Encode()
+	}
+	if example.body != nil {
+		request.Header = append(request.Header, postmanKeyValue{Key: "Content-Type", Value: example.contentType})
+		request.Body = &postmanBody{Mode: "raw", Raw: string(example.body)}
+		if strings.Contains(example.contentType, "json") {
+			request.Body.Options = map[string]interface{}{"raw": map[string]string{"language": "json"}}
+		}
+	}
+	return request
+}
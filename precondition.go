@@ -0,0 +1,100 @@
+package webapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	//ErrPreconditionFailed The If-Match or If-Unmodified-Since condition is false (412)
+	ErrPreconditionFailed = errors.New("the precondition of request is not satisfied")
+
+	//ErrPreconditionRequired The request must be conditional, see RequirePrecondition (428)
+	ErrPreconditionRequired = errors.New("the request must contain If-Match or If-Unmodified-Since")
+)
+
+type (
+	//preconditionGuard the middleware which requires the conditional headers
+	preconditionGuard struct {
+		methods map[string]bool
+	}
+)
+
+//Precondition Evaluate If-Match and If-Unmodified-Since (RFC 7232) with the current state of resource,
+//the error with 412 Precondition Failed is returned if the condition is false (return it from action to reply it).
+//The empty etag means the resource does not exist, the unquoted etag will be quoted before comparison,
+//If-Unmodified-Since is evaluated only if If-Match is absent and the last modified time is provided.
+func (ctx *Context) Precondition(currentETag string, lastModified ...time.Time) error {
+	if match := ctx.r.Header.Get("If-Match"); len(match) > 0 {
+		if !matchETag(match, currentETag) {
+			return NewHTTPError(http.StatusPreconditionFailed, ErrPreconditionFailed)
+		}
+		return nil
+	}
+	if since := ctx.r.Header.Get("If-Unmodified-Since"); len(since) > 0 && len(lastModified) > 0 && !lastModified[0].IsZero() {
+		if date, err := http.ParseTime(since); err == nil && lastModified[0].Truncate(time.Second).After(date) {
+			return NewHTTPError(http.StatusPreconditionFailed, ErrPreconditionFailed)
+		}
+	}
+	return nil
+}
+
+//RequirePrecondition The middleware which replies 428 Precondition Required if the request of methods
+//(default is PUT and PATCH) contains neither If-Match nor If-Unmodified-Since, apply it to the routes to be protected
+//from lost updates and evaluate the condition via ctx.Precondition in actions.
+func RequirePrecondition(methods ...string) Middleware {
+	if len(methods) == 0 {
+		methods = []string{http.MethodPut, http.MethodPatch}
+	}
+	guard := &preconditionGuard{methods: map[string]bool{}}
+	for _, method := range methods {
+		guard.methods[strings.ToUpper(method)] = true
+	}
+	return guard
+}
+
+func (guard *preconditionGuard) Invoke(ctx *Context, next HTTPHandler) {
+	if guard.methods[ctx.r.Method] && len(ctx.r.Header.Get("If-Match")) == 0 && len(ctx.r.Header.Get("If-Unmodified-Since")) == 0 {
+		ctx.ReplyError(http.StatusPreconditionRequired, ErrPreconditionRequired)
+		return
+	}
+	next(ctx)
+}
+
+//matchETag the strong comparison of If-Match (weak tags never match), "*" matches any existing resource
+func matchETag(match string, current string) bool {
+	if len(current) == 0 {
+		return false
+	}
+	if strings.TrimSpace(match) == "*" {
+		return true
+	}
+	if strings.HasPrefix(current, "W/") {
+		return false
+	}
+	if !strings.HasPrefix(current, "\"") {
+		current = "\"" + current + "\""
+	}
+	for match = strings.TrimLeft(match, " \t,"); len(match) > 0; match = strings.TrimLeft(match, " \t,") {
+		weak := strings.HasPrefix(match, "W/")
+		if weak {
+			match = match[2:]
+		}
+		//the entity tag is quoted and might contain commas
+		end := len(match)
+		if strings.HasPrefix(match, "\"") {
+			if index := strings.IndexByte(match[1:], '"'); index >= 0 {
+				end = index + 2
+			}
+		} else if index := strings.IndexAny(match, " \t,"); index >= 0 {
+			end = index
+		}
+		if !weak && match[:end] == current {
+			return true
+		}
+		match = match[end:]
+	}
+	return false
+}
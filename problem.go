@@ -0,0 +1,91 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type (
+	//ProblemDetails Problem details of RFC 7807, it can be returned as error to be replied by ProblemHandler
+	ProblemDetails struct {
+		Type     string `json:"type,omitempty"`
+		Title    string `json:"title,omitempty"`
+		Status   int    `json:"status,omitempty"`
+		Detail   string `json:"detail,omitempty"`
+		Instance string `json:"instance,omitempty"`
+
+		//Extensions The extension members which will be flattened into the document
+		Extensions map[string]interface{} `json:"-"`
+	}
+)
+
+//NewProblem Create problem details with status and detail
+func NewProblem(httpstatus int, detail string) *ProblemDetails {
+	return &ProblemDetails{
+		Title:  http.StatusText(httpstatus),
+		Status: httpstatus,
+		Detail: detail,
+	}
+}
+
+func (problem *ProblemDetails) Error() string {
+	if len(problem.Detail) > 0 {
+		return problem.Detail
+	}
+	return problem.Title
+}
+
+//StatusCode HTTP Status Code
+func (problem *ProblemDetails) StatusCode() int {
+	return problem.Status
+}
+
+//MarshalJSON The extension members are flattened (the standard members take precedence)
+func (problem ProblemDetails) MarshalJSON() ([]byte, error) {
+	type members ProblemDetails
+	data, err := json.Marshal(members(problem))
+	if err != nil || len(problem.Extensions) == 0 {
+		return data, err
+	}
+	document := map[string]interface{}{}
+	for name, value := range problem.Extensions {
+		document[name] = value
+	}
+	if err = json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(document)
+}
+
+//ProblemHandler The error handler which replies application/problem+json documents,
+//set it via SetErrorHandler to render the framework errors (404, 405, 400, 500 etc.), HTTPError and ProblemDetails
+func ProblemHandler(ctx *Context, httpstatus int, err error) {
+	problem := &ProblemDetails{}
+	var details *ProblemDetails
+	if errors.As(err, &details) {
+		*problem = *details
+	} else if err != nil {
+		problem.Detail = err.Error()
+	}
+	if problem.Status == 0 {
+		problem.Status = httpstatus
+	}
+	if len(problem.Title) == 0 {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.Detail == problem.Title {
+		//the detail generated by framework is usually the same as title
+		problem.Detail = ""
+	}
+	if len(problem.Instance) == 0 && ctx.r != nil {
+		problem.Instance = ctx.r.URL.Path
+	}
+	data, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		ctx.Reply(problem.Status, marshalErr)
+		return
+	}
+	ctx.w.Header().Set("Content-Type", "application/problem+json")
+	ctx.Write(problem.Status, data)
+}
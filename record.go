@@ -0,0 +1,177 @@
+package webapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+type (
+	//Recording The request recorded by Record middleware
+	Recording struct {
+		Time       time.Time
+		Method     string
+		URL        string
+		Host       string
+		RemoteAddr string
+		Header     http.Header
+		Body       []byte
+		//Status The status code replied
+		Status int
+	}
+
+	//RecordSink The storage of recordings (the credential headers are redacted by default, see RecordCredentials)
+	RecordSink interface {
+		Record(*Recording) error
+	}
+
+	//RecordSinkFunc Function as RecordSink
+	RecordSinkFunc func(*Recording) error
+
+	//jsonLinesSink the sink which writes recordings as JSON lines
+	jsonLinesSink struct {
+		mutex   sync.Mutex
+		encoder *json.Encoder
+	}
+
+	//RecordOption Option of Record middleware
+	RecordOption func(*recorder)
+
+	//recorder the middleware which records requests
+	recorder struct {
+		sink        RecordSink
+		filter      func(*Context) bool
+		credentials bool
+		onError     func(*Context, error)
+	}
+)
+
+//redactedHeaders the credential headers which are redacted from recordings by default
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key", "X-Auth-Token"}
+
+//Record The middleware which records full requests (method, URL, headers and body) into sink,
+//the requests can be re-executed by host.Replay, only the requests accepted by filter will be recorded if it is not nil.
+//The credential headers are redacted and the errors of sink are reported to the PanicHandler (or the error handler) of host by default.
+func Record(sink RecordSink, filter func(*Context) bool, opts ...RecordOption) Middleware {
+	m := &recorder{sink: sink, filter: filter}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+//RecordCredentials The credential headers (e.g. Authorization and Cookie) are recorded verbatim,
+//e.g. to replay authenticated requests (the sink must be protected)
+func RecordCredentials() RecordOption {
+	return func(m *recorder) {
+		m.credentials = true
+	}
+}
+
+//RecordErrors The errors of sink will be passed to handler instead of the PanicHandler of host
+func RecordErrors(handler func(ctx *Context, err error)) RecordOption {
+	return func(m *recorder) {
+		m.onError = handler
+	}
+}
+
+func (m *recorder) Invoke(ctx *Context, next HTTPHandler) {
+	if m.filter != nil && !m.filter(ctx) {
+		next(ctx)
+		return
+	}
+	recording := &Recording{
+		Time:       time.Now(),
+		Method:     ctx.r.Method,
+		URL:        ctx.r.URL.RequestURI(),
+		Host:       ctx.r.Host,
+		RemoteAddr: ctx.r.RemoteAddr,
+		Header:     ctx.r.Header.Clone(),
+	}
+	if !m.credentials {
+		for _, name := range redactedHeaders {
+			if values := recording.Header[name]; len(values) > 0 {
+				recording.Header[name] = []string{"[REDACTED]"}
+			}
+		}
+	}
+	if ctx.r.Body != nil && ctx.r.Body != http.NoBody {
+		//the body is buffered and still readable by handler
+		recording.Body = append([]byte{}, ctx.Body()...)
+	}
+	next(ctx)
+	recording.Status = ctx.statuscode
+	if err := m.sink.Record(recording); err != nil {
+		ctx.sinkError(m.onError, err)
+	}
+}
+
+//sinkError report the error of sink to handler, or the PanicHandler (or the error handler) of host
+func (ctx *Context) sinkError(handler func(*Context, error), err error) {
+	switch {
+	case handler != nil:
+		handler(ctx, err)
+	case ctx.host != nil && ctx.host.conf.PanicHandler != nil:
+		ctx.host.conf.PanicHandler(ctx, err, debug.Stack())
+	default:
+		//the error handler of host (the response has been replied usually)
+		ctx.ReplyError(http.StatusInternalServerError, err)
+	}
+}
+
+//Record Record via function
+func (f RecordSinkFunc) Record(recording *Recording) error {
+	return f(recording)
+}
+
+//NewJSONLinesSink Create the sink which writes recordings into w as JSON lines (see LoadRecordings)
+func NewJSONLinesSink(w io.Writer) RecordSink {
+	return &jsonLinesSink{encoder: json.NewEncoder(w)}
+}
+
+func (sink *jsonLinesSink) Record(recording *Recording) error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	return sink.encoder.Encode(recording)
+}
+
+//LoadRecordings Read the recordings written by JSON lines sink
+func LoadRecordings(r io.Reader) ([]*Recording, error) {
+	var recordings []*Recording
+	decoder := json.NewDecoder(r)
+	for {
+		recording := &Recording{}
+		if err := decoder.Decode(recording); err == io.EOF {
+			return recordings, nil
+		} else if err != nil {
+			return recordings, err
+		}
+		recordings = append(recordings, recording)
+	}
+}
+
+//Replay Re-execute the recorded request against the current handlers and return the response
+func (host *Host) Replay(recording *Recording) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(recording.Method, recording.URL, bytes.NewReader(recording.Body))
+	if len(recording.Body) == 0 {
+		r.Body = http.NoBody
+	}
+	r.Header = recording.Header.Clone()
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	if len(recording.Host) > 0 {
+		r.Host = recording.Host
+	}
+	if len(recording.RemoteAddr) > 0 {
+		r.RemoteAddr = recording.RemoteAddr
+	}
+	w := httptest.NewRecorder()
+	host.ServeHTTP(w, r)
+	return w
+}
@@ -3,11 +3,12 @@ package webapi
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"reflect"
-	"strconv"
 	"strings"
+	"sync"
 )
 
 type (
@@ -16,12 +17,21 @@ type (
 		ContextArgs []reflect.Type //Construct Parameters for Context
 		Context     reflect.Type   //Context
 		Function    reflect.Value  //Actual Function
+		initArgs    []converter    //Converters of ContextArgs
+		initIndex   int            //Index of Init method in the method set of Context
+		dispatch    Dispatch       //Generated dispatch (reflection is skipped)
+		values      sync.Pool      //Pool of argument slices sized by arity
+		arity       int            //Capacity of argument slices (the larger of Init and the function)
+		permissions []string       //Permissions declared by the tags of query and body
 	}
 
 	param struct {
 		reflect.Type
 		isBody  bool
 		isQuery bool
+		plan    *bindingPlan //Binding plan of query
+		convert converter    //Converter of path parameter
+		checker int          //Index of Check method, -1 if not existed
 	}
 )
 
@@ -34,35 +44,48 @@ var types = struct {
 }
 
 func (method *function) run(ctx *Context, arguments ...string) (objs []interface{}) {
-	args := make([]reflect.Value, 0)
+	if method.dispatch != nil {
+		//the generated code is preferred
+		objs, err := method.dispatch(ctx, arguments)
+		if err != nil {
+			if ctx.statuscode == 0 {
+				ctx.ReplyError(errorStatus(err, http.StatusBadRequest), err)
+			}
+			return nil
+		}
+		return objs
+	}
+	values := method.getValues()
+	defer method.putValues(values)
+	args := (*values)[:0]
 	if method.Context != nil {
 		obj, callback := createObj(method.Context)
 		if setController(obj, reflect.ValueOf(interface{}(ctx).(Controller))) {
 			var err error
-			//init controller
-			arguments, err = initController(obj, method, arguments...)
+			//init controller (the slice is reused by the arguments of function after Init)
+			arguments, err = initController(obj, method, args, arguments...)
 			if err != nil {
 				if ctx.statuscode == 0 {
-					ctx.Reply(http.StatusBadRequest, err.Error())
+					ctx.ReplyError(errorStatus(err, http.StatusBadRequest), err)
 				}
 				return
 			}
 		} else {
-			ctx.Reply(http.StatusNotFound)
+			ctx.ReplyError(http.StatusNotFound, nil)
 			return
 		}
 		args = append(args, callback(obj))
 	}
 	//analyse the params with context instance
-	paramArgs, err := ctx.analyseParams(method.Args, arguments...)
+	args, err := ctx.analyseParams(args, method.Args, arguments...)
 	if err != nil {
 		if ctx.statuscode == 0 {
-			ctx.Reply(http.StatusBadRequest, err.Error())
+			ctx.ReplyError(errorStatus(err, http.StatusBadRequest), err)
 		}
 		return
 	}
 	//call the function
-	result := method.Function.Call(append(args, paramArgs...))
+	result := method.Function.Call(args)
 	objs = make([]interface{}, len(result))
 	for index, res := range result {
 		objs[index] = res.Interface()
@@ -70,6 +93,24 @@ func (method *function) run(ctx *Context, arguments ...string) (objs []interface
 	return
 }
 
+//getValues get an empty argument slice from pool
+func (method *function) getValues() *[]reflect.Value {
+	if values, _ := method.values.Get().(*[]reflect.Value); values != nil {
+		return values
+	}
+	values := make([]reflect.Value, 0, method.arity)
+	return &values
+}
+
+//putValues clear the argument slice and put it back into pool
+func (method *function) putValues(values *[]reflect.Value) {
+	all := (*values)[:cap(*values)]
+	for index := range all {
+		all[index] = reflect.Value{}
+	}
+	method.values.Put(values)
+}
+
 func (method *function) MakeHandler() func(ctx *Context, args ...string) {
 	return func(ctx *Context, args ...string) {
 		//endpoint is constructed and executable
@@ -77,6 +118,13 @@ func (method *function) MakeHandler() func(ctx *Context, args ...string) {
 		if ctx.statuscode == 0 && len(reply) > 0 {
 			//if status code is zero, means the reply didn't handle by method
 			//try to reply with the return value
+			for _, result := range reply {
+				if err, isErr := result.(error); isErr && errorStatus(err, 0) != 0 {
+					//the typed errors (e.g. HTTPError) are replied via error handler
+					ctx.ReplyError(errorStatus(err, 0), err)
+					return
+				}
+			}
 			response, isResp := reply[0].(Replyable)
 			if !isResp {
 				response = &Reply{
@@ -125,46 +173,43 @@ func (p *param) loadFromBytes(body []byte, serializer Serializer) (*reflect.Valu
 	return &obj, err
 }
 
+//loadFromReader Load object from reader (nil reader means empty body)
+func (p *param) loadFromReader(r io.Reader, stream StreamDeserializer) (*reflect.Value, error) {
+	var err error
+	obj, callback := createObj(p.Type)
+	if r != nil {
+		err = stream.UnmarshalStream(r, obj.Addr().Interface())
+	}
+	obj = callback(obj)
+	return &obj, err
+}
+
 //loadFromValues Load object from url.Values
 func (p *param) loadFromValues(queries url.Values) (*reflect.Value, error) {
 	obj, callback := createObj(p.Type)
 	if len(queries) > 0 {
-		setObj(obj, queries)
-		obj = callback(obj)
-	} else {
-		obj = callback(obj)
+		plan := p.plan
+		if plan == nil {
+			plan = planOf(p.Type)
+		}
+		plan.bind(obj, queries)
 	}
+	obj = callback(obj)
 	return &obj, nil
 }
 
-func setObj(value reflect.Value, queries url.Values) {
-	t := value.Type()
-	if t.Kind() != reflect.Struct {
-		return
+//queryFields list the fields which can be assigned from query (derived from binding plan),
+//the names of fields are replaced by the query names
+func queryFields(t reflect.Type) (fields []reflect.StructField) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
 	}
-	for i := 0; i < value.NumField(); i++ {
-		field := value.Field(i)
-		if field.Kind() == reflect.Struct {
-			setObj(field, queries)
-			continue
-		}
-		if field.CanSet() {
-			ftyp := t.Field(i)
-			name := strings.Split(ftyp.Tag.Get("json"), ",")[0]
-			if len(name) == 0 {
-				name = ftyp.Name
-			}
-		detect:
-			if len(name) > 0 && name != "-" {
-				if _, existed := (map[string][]string)(queries)[name]; existed {
-					setValue(field, queries.Get(name))
-				} else if lower := strings.ToLower(name); lower != name {
-					name = lower
-					goto detect
-				}
-			}
-		}
+	for _, plan := range planOf(t).fields {
+		field := t.FieldByIndex(plan.index)
+		field.Name = plan.names[0]
+		fields = append(fields, field)
 	}
+	return
 }
 
 //createObj Create writable object and return a function which can set back to actual type
@@ -185,53 +230,7 @@ func createObj(typ reflect.Type) (reflect.Value, func(reflect.Value) reflect.Val
 
 //setValue Set value to reflect.Value
 func setValue(value reflect.Value, data string) (err error) {
-	switch value.Type().Kind() {
-	case reflect.String:
-		value.SetString(data)
-		break
-	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
-		val, _ := strconv.ParseInt(data, 10, 64)
-		value.SetInt(val)
-		break
-	case reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uint8, reflect.Uint16:
-		val, _ := strconv.ParseUint(data, 10, 64)
-		value.SetUint(val)
-		break
-	case reflect.Float32, reflect.Float64:
-		val, _ := strconv.ParseFloat(data, 64)
-		value.SetFloat(val)
-		break
-	case reflect.Bool:
-		value.SetBool(strings.ToLower(data) == "true")
-		break
-	case reflect.Array:
-		return setArray(value, strings.Split(data, ","))
-	case reflect.Slice:
-		if array := strings.Split(data, ","); len(array[0]) > 0 {
-			value.Set(reflect.MakeSlice(reflect.SliceOf(value.Type().Elem()), len(array), len(array)))
-			return setArray(value, array)
-		}
-		break
-	case reflect.Ptr:
-		value.Set(reflect.New(value.Type().Elem()))
-		err = setValue(value.Elem(), data)
-		break
-	}
-	return
-}
-
-//setArray Set array value to reflect.Value
-func setArray(value reflect.Value, data []string) (err error) {
-	cap := value.Len()
-	if cap > len(data) {
-		cap = len(data)
-	}
-	for index := 0; index < cap; index++ {
-		if err = setValue(value.Index(index), data[index]); err != nil {
-			return err
-		}
-	}
-	return nil
+	return converterOf(value.Type())(value, data)
 }
 
 //setController assign to actual field if there is a embedded controller
@@ -258,38 +257,43 @@ func setController(value reflect.Value, controller reflect.Value) bool {
 }
 
 //initController run init function
-func initController(obj reflect.Value, method *function, arguments ...string) ([]string, error) {
-	preArgs := []reflect.Value{}
+func initController(obj reflect.Value, method *function, preArgs []reflect.Value, arguments ...string) ([]string, error) {
 	if method.ContextArgs != nil {
 		//means preconditions required or ctx parameter existed
 		for index, arg := range method.ContextArgs {
 			val := reflect.New(arg).Elem()
-			if err := setValue(val, arguments[index]); err != nil {
+			if err := method.initArgs[index](val, arguments[index]); err != nil {
 				return nil, errors.New(http.StatusText(http.StatusBadRequest))
 			}
 			preArgs = append(preArgs, val)
 		}
 		arguments = arguments[len(method.ContextArgs):]
 		//call init function with parameters which are provided by path(query is excluded)
-		if err := obj.Addr().MethodByName("Init").Call(preArgs)[0]; err.Interface() != nil {
+		if err := obj.Addr().Method(method.initIndex).Call(preArgs)[0]; err.Interface() != nil {
 			return nil, err.Interface().(error)
 		}
 	}
 	return arguments, nil
 }
 
-//analyseParams assign value to params
-func (ctx *Context) analyseParams(params []*param, arguments ...string) ([]reflect.Value, error) {
+//analyseParams assign value to params, the values are appended to args
+func (ctx *Context) analyseParams(args []reflect.Value, params []*param, arguments ...string) ([]reflect.Value, error) {
 	var index = 0
-	var args = []reflect.Value{}
 	for _, arg := range params {
 		var val reflect.Value
 		if arg.isBody {
 			//load body structure from body with serializer(default will be JSON)
-			if ctx.Deserializer != nil {
-				var body = ctx.Body()
-				if ctx.BeforeReading != nil {
-					body = ctx.BeforeReading(body)
+			if stream, reader := ctx.bodyStream(); stream != nil {
+				//deserialize from body directly without buffering
+				obj, err := arg.loadFromReader(reader, stream)
+				if err != nil {
+					return nil, ctx.streamError(err)
+				}
+				val = *obj
+			} else if ctx.Deserializer != nil {
+				body, err := ctx.bindingBody()
+				if err != nil {
+					return nil, err
 				}
 				obj, err := arg.Load(body, ctx.Deserializer)
 				if err != nil {
@@ -311,13 +315,13 @@ func (ctx *Context) analyseParams(params []*param, arguments ...string) ([]refle
 		} else {
 			//it's a simple param from path(not query)
 			val = reflect.New(arg.Type).Elem()
-			if err := setValue(val, arguments[index]); err != nil {
+			if err := arg.convert(val, arguments[index]); err != nil {
 				return nil, err
 			}
 			index++
 		}
 		//run checker
-		if err := runChecker(val); err != nil {
+		if err := arg.check(val); err != nil {
 			return nil, err
 		} else if arg.isQuery {
 			val = val.Elem()
@@ -327,15 +331,13 @@ func (ctx *Context) analyseParams(params []*param, arguments ...string) ([]refle
 	return args, nil
 }
 
-//runChecker invoke Check function to validate transferring entity
-func runChecker(val reflect.Value, checkername ...string) (err error) {
-	if len(checkername) == 0 {
-		checkername = []string{"Check"}
+//check invoke the precompiled Check method of parameter
+func (p *param) check(val reflect.Value) error {
+	if p.checker == -1 {
+		return nil
 	}
-	if checker := val.MethodByName(checkername[0]); checker.IsValid() && checker.Type().NumIn() == 0 && checker.Type().NumOut() == 1 && checker.Type().Out(0) == types.Error {
-		if err := checker.Call(make([]reflect.Value, 0))[0].Interface(); err != nil {
-			return err.(error)
-		}
+	if err := val.Method(p.checker).Call(nil)[0].Interface(); err != nil {
+		return err.(error)
 	}
 	return nil
 }
@@ -0,0 +1,124 @@
+package webapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	//RouteInfo The registered route
+	RouteInfo struct {
+		Method      string
+		Path        string
+		Tenant      string   `json:",omitempty"`
+		Controller  string   `json:",omitempty"`
+		Action      string   `json:",omitempty"`
+		Middlewares []string `json:",omitempty"`
+		Permissions []string `json:",omitempty"`
+
+		//Params The types of path parameters in order (including the parameters of Init)
+		Params []reflect.Type `json:"-"`
+		//Query The type of query structure
+		Query reflect.Type `json:"-"`
+		//Body The type of body structure
+		Body reflect.Type `json:"-"`
+		//Return The type of first return value
+		Return reflect.Type `json:"-"`
+
+		middlewares []Middleware
+	}
+)
+
+//newRoute complete the route with the tenant and middlewares of registration
+func (host *Host) newRoute(info RouteInfo, middlewares []Middleware) *RouteInfo {
+	if host.scope != nil {
+		info.Tenant = host.scope.id
+	}
+	info.middlewares = append([]Middleware{}, middlewares...)
+	info.Middlewares = middlewareNames(middlewares)
+	return &info
+}
+
+//addRoute record the route into route table
+func (host *Host) addRoute(route *RouteInfo) {
+	host.routes = append(host.routes, *route)
+	if count := strings.Count(route.Path, "{"); count > host.maxArguments {
+		host.maxArguments = count
+	}
+	if host.lookups != nil {
+		//the cached lookups might be shadowed by the new route
+		host.lookups.Purge()
+	}
+}
+
+//serve the handler which marks the route of context before the middlewares of route
+func (route *RouteInfo) serve(handler httpHandler) httpHandler {
+	return func(ctx *Context, args ...string) {
+		ctx.route, ctx.routeArgs = route, args
+		handler(ctx, args...)
+	}
+}
+
+//Route The route selected for the request, it must not be modified
+//(nil if no route is found or the route is not selected yet, e.g. in the middlewares of tenant)
+func (ctx *Context) Route() *RouteInfo {
+	return ctx.route
+}
+
+//Report The registered routes sorted by path, method and tenant (stable between builds)
+func (host *Host) Report() []RouteInfo {
+	routes := host.registeredRoutes()
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		if routes[i].Method != routes[j].Method {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Tenant < routes[j].Tenant
+	})
+	return routes
+}
+
+//registeredRoutes the copy of routes in registration order
+func (host *Host) registeredRoutes() []RouteInfo {
+	host.mutex.RLock()
+	defer host.mutex.RUnlock()
+	return append([]RouteInfo{}, host.routes...)
+}
+
+//setTypes set the bound types from function
+func (info *RouteInfo) setTypes(method *function) {
+	info.Params = append([]reflect.Type{}, method.ContextArgs...)
+	for _, arg := range method.Args {
+		if arg.isBody {
+			info.Body = arg.Type
+		} else if arg.isQuery {
+			info.Query = arg.Type
+		} else {
+			info.Params = append(info.Params, arg.Type)
+		}
+	}
+	if method.Function.IsValid() && method.Function.Type().NumOut() > 0 {
+		info.Return = method.Function.Type().Out(0)
+	}
+}
+
+//controllerName the name of controller type
+func controllerName(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Name()
+}
+
+//middlewareNames the type names of middlewares
+func middlewareNames(middlewares []Middleware) []string {
+	names := make([]string, len(middlewares))
+	for index, middleware := range middlewares {
+		names[index] = fmt.Sprintf("%T", middleware)
+	}
+	return names
+}
@@ -0,0 +1,154 @@
+package webapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+type (
+	//connKey the key of the connection in the context of request
+	connKey struct{}
+)
+
+//OnStart Add the hook which will be executed in registration order after the listener is bound and before the host
+//starts serving, the startup will be aborted if any of them returns error
+func (host *Host) OnStart(hook func(context.Context) error) *Host {
+	host.onStart = append(host.onStart, hook)
+	return host
+}
+
+//OnStop Add the hook which will be executed in registration order while the host is shutting down,
+//they run alongside the draining of active requests, so the hooks closing long-lived streams (e.g. sse.Hub)
+//let the shutdown complete
+func (host *Host) OnStop(hook func(context.Context) error) *Host {
+	host.onStop = append(host.onStop, hook)
+	return host
+}
+
+//Run Listen on the TCP network address and serve with the host,
+//http.ErrServerClosed is returned after Shutdown (even if Shutdown is called before Run)
+func (host *Host) Run(addr string) error {
+	if len(addr) == 0 {
+		addr = ":http"
+	}
+	server, listener, err := host.listen(addr)
+	if err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+//RunTLS Listen on the TCP network address and serve HTTPS with the host, the TLS configuration is TLSConfig of Config
+//(TLSIntermediate by default). The files can be empty if the certificates are provided by TLSConfig.
+func (host *Host) RunTLS(addr string, certFile string, keyFile string) error {
+	if len(addr) == 0 {
+		addr = ":https"
+	}
+	server, listener, err := host.listen(addr)
+	if err != nil {
+		return err
+	}
+	server.TLSConfig = host.tlsConfig()
+	return server.ServeTLS(listener, certFile, keyFile)
+}
+
+//Shutdown Gracefully shut down the server started by Run helpers and execute the stop hooks during the draining,
+//all of the stop hooks will be executed and the first error will be returned. The host cannot be run after that.
+func (host *Host) Shutdown(ctx context.Context) (err error) {
+	host.mutex.Lock()
+	host.closed = true
+	server := host.server
+	host.mutex.Unlock()
+	done := make(chan error, 1)
+	if server != nil {
+		go func() {
+			done <- server.Shutdown(ctx)
+		}()
+	} else {
+		done <- nil
+	}
+	for _, hook := range host.onStop {
+		if hookErr := hook(ctx); hookErr != nil && err == nil {
+			err = hookErr
+		}
+	}
+	if serverErr := <-done; serverErr != nil {
+		err = serverErr
+	}
+	return
+}
+
+//listen create the server and bind the address, then execute the start hooks
+func (host *Host) listen(addr string) (*http.Server, net.Listener, error) {
+	server, err := host.newServer(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = host.start(); err != nil {
+		listener.Close()
+		return nil, nil, err
+	}
+	return server, listener, nil
+}
+
+//start execute the start hooks
+func (host *Host) start() error {
+	for _, hook := range host.onStart {
+		if err := hook(context.Background()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//newServer create http server with the timeouts from config, http.ErrServerClosed is returned if the host is shut down
+func (host *Host) newServer(addr string) (*http.Server, error) {
+	host.initCheck()
+	host.mutex.Lock()
+	defer host.mutex.Unlock()
+	if host.closed {
+		return nil, http.ErrServerClosed
+	}
+	host.server = &http.Server{
+		Addr:              addr,
+		Handler:           host,
+		ReadHeaderTimeout: timeout(host.conf.ReadHeaderTimeout),
+		ReadTimeout:       timeout(host.conf.ReadTimeout),
+		WriteTimeout:      timeout(host.conf.WriteTimeout),
+		IdleTimeout:       timeout(host.conf.IdleTimeout),
+		MaxHeaderBytes:    host.conf.MaxHeaderBytes,
+		//the connection is kept for clearing the write deadline of long-lived responses
+		ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+			return context.WithValue(ctx, connKey{}, conn)
+		},
+	}
+	return host.server, nil
+}
+
+//SetWriteDeadline Override the write deadline of the response (the zero time means no deadline),
+//e.g. for long-lived responses which should not be limited by the WriteTimeout of host
+func (ctx *Context) SetWriteDeadline(deadline time.Time) error {
+	if setter, isSetter := ctx.w.(interface{ SetWriteDeadline(time.Time) error }); isSetter {
+		return setter.SetWriteDeadline(deadline)
+	}
+	if conn, isConn := ctx.r.Context().Value(connKey{}).(net.Conn); isConn && ctx.r.ProtoMajor == 1 {
+		//the deadline will be set again by net/http for the next request of connection
+		return conn.SetWriteDeadline(deadline)
+	}
+	return errors.New("the response writer does not support write deadline")
+}
+
+//timeout negative value means no timeout
+func timeout(duration time.Duration) time.Duration {
+	if duration < 0 {
+		return 0
+	}
+	return duration
+}
@@ -0,0 +1,305 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-webapi/webapi"
+)
+
+type (
+	//Options Options of hub
+	Options struct {
+		//History The count of latest events kept for Last-Event-ID replay, default is 100, negative means no replay
+		History int
+
+		//Buffer The size of event queue per subscriber, default is 32.
+		//The subscriber will be dropped if the queue is full, and it can catch up via replay after reconnecting.
+		Buffer int
+
+		//KeepAlive The interval of keep-alive comments, default is 15s, negative means no keep-alive
+		KeepAlive time.Duration
+
+		//Retry The reconnection time suggested to clients
+		Retry time.Duration
+
+		//Serializer The serializer of event data which is not string or bytes, default is JSON
+		Serializer webapi.Serializer
+	}
+
+	//Message Published event
+	Message struct {
+		ID    string
+		Topic string
+		Event string
+		Data  interface{}
+
+		seq     uint64
+		payload string
+	}
+
+	//Subscription Topics and filter of subscriber
+	Subscription struct {
+		//Topics The subscribed topics, empty means all topics
+		Topics []string
+
+		//Filter Filter the messages for subscriber (e.g. by user), nil means all messages of topics
+		Filter func(msg *Message) bool
+	}
+
+	//Hub Server-sent events hub which publishes messages to subscribers of topics
+	Hub struct {
+		opts        Options
+		epoch       string
+		mu          sync.Mutex
+		seq         uint64
+		history     []*Message
+		subscribers map[*subscriber]struct{}
+		closed      bool
+		wg          sync.WaitGroup
+	}
+
+	subscriber struct {
+		topics map[string]bool
+		filter func(msg *Message) bool
+		events chan *Message
+	}
+)
+
+//ErrHubClosed The hub has been closed
+var ErrHubClosed = errors.New("sse: hub closed")
+
+//NewHub Create the hub, the subscribers will be closed while the host is shutting down
+func NewHub(host *webapi.Host, opts Options) *Hub {
+	if opts.History == 0 {
+		opts.History = 100
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = 32
+	}
+	if opts.KeepAlive == 0 {
+		opts.KeepAlive = 15 * time.Second
+	}
+	if opts.Serializer == nil {
+		opts.Serializer = webapi.Serializers["application/json"]
+	}
+	hub := &Hub{
+		opts: opts,
+		//the ids of previous process will be treated as unknown
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		subscribers: map[*subscriber]struct{}{},
+	}
+	if host != nil {
+		host.OnStop(hub.Close)
+	}
+	return hub
+}
+
+//Publish Publish the event to subscribers of topic and return the event id
+func (hub *Hub) Publish(topic string, event string, data interface{}) (string, error) {
+	msg := &Message{
+		Topic: topic,
+		Event: event,
+		Data:  data,
+	}
+	switch value := data.(type) {
+	case string:
+		msg.payload = value
+	case []byte:
+		msg.payload = string(value)
+	default:
+		payload, err := hub.opts.Serializer.Marshal(data)
+		if err != nil {
+			return "", err
+		}
+		msg.payload = string(payload)
+	}
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if hub.closed {
+		return "", ErrHubClosed
+	}
+	hub.seq++
+	msg.seq, msg.ID = hub.seq, hub.epoch+"-"+strconv.FormatUint(hub.seq, 10)
+	if hub.opts.History > 0 {
+		if len(hub.history) >= hub.opts.History {
+			hub.history = append(hub.history[:0], hub.history[len(hub.history)-hub.opts.History+1:]...)
+		}
+		hub.history = append(hub.history, msg)
+	}
+	for sub := range hub.subscribers {
+		if !sub.accept(msg) {
+			continue
+		}
+		select {
+		case sub.events <- msg:
+		default:
+			//drop the slow subscriber
+			delete(hub.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	return msg.ID, nil
+}
+
+//Handler Create the endpoint handler, the topics will be read from query "topic" if subscribe is nil
+func (hub *Hub) Handler(subscribe func(ctx *webapi.Context) (Subscription, error)) webapi.HTTPHandler {
+	return func(ctx *webapi.Context) {
+		sub := Subscription{}
+		if subscribe != nil {
+			var err error
+			if sub, err = subscribe(ctx); err != nil {
+				ctx.ReplyError(http.StatusBadRequest, err)
+				return
+			}
+		} else {
+			sub.Topics = ctx.GetRequest().URL.Query()["topic"]
+		}
+		if err := hub.Serve(ctx, sub); err != nil && ctx.StatusCode() == 0 {
+			status := http.StatusInternalServerError
+			if err == ErrHubClosed {
+				status = http.StatusServiceUnavailable
+			}
+			ctx.ReplyError(status, err)
+		}
+	}
+}
+
+//Serve Stream the events to client until it is gone, dropped or the hub is closed.
+//The missed events will be replayed first if the Last-Event-ID is in history.
+func (hub *Hub) Serve(ctx *webapi.Context, subscription Subscription) error {
+	sub := &subscriber{
+		filter: subscription.Filter,
+		events: make(chan *Message, hub.opts.Buffer),
+	}
+	if len(subscription.Topics) > 0 {
+		sub.topics = map[string]bool{}
+		for _, topic := range subscription.Topics {
+			sub.topics[topic] = true
+		}
+	}
+	hub.mu.Lock()
+	if hub.closed {
+		hub.mu.Unlock()
+		return ErrHubClosed
+	}
+	replay := hub.replay(ctx.GetRequest().Header.Get("Last-Event-ID"), sub)
+	hub.subscribers[sub] = struct{}{}
+	hub.wg.Add(1)
+	hub.mu.Unlock()
+	defer hub.wg.Done()
+	defer hub.unsubscribe(sub)
+	stream, err := ctx.EventStream()
+	if err != nil {
+		return err
+	}
+	if hub.opts.Retry > 0 {
+		if err = stream.Send(webapi.Event{Retry: hub.opts.Retry}); err != nil {
+			return err
+		}
+	}
+	for _, msg := range replay {
+		if err = send(stream, msg); err != nil {
+			return err
+		}
+	}
+	var keepalive <-chan time.Time
+	if hub.opts.KeepAlive > 0 {
+		ticker := time.NewTicker(hub.opts.KeepAlive)
+		defer ticker.Stop()
+		keepalive = ticker.C
+	}
+	for {
+		select {
+		case msg, open := <-sub.events:
+			if !open {
+				return nil
+			}
+			if err = send(stream, msg); err != nil {
+				return err
+			}
+		case <-keepalive:
+			if err = stream.Comment("keep-alive"); err != nil {
+				return err
+			}
+		case <-stream.Done():
+			return nil
+		}
+	}
+}
+
+//Subscribers The count of subscribers
+func (hub *Hub) Subscribers() int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	return len(hub.subscribers)
+}
+
+//Close Close all subscribers and wait until their streams are finished
+func (hub *Hub) Close(ctx context.Context) error {
+	hub.mu.Lock()
+	hub.closed = true
+	for sub := range hub.subscribers {
+		delete(hub.subscribers, sub)
+		close(sub.events)
+	}
+	hub.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		hub.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//replay the messages after last event id in history
+func (hub *Hub) replay(lastEventID string, sub *subscriber) []*Message {
+	if len(lastEventID) == 0 || len(hub.history) == 0 {
+		return nil
+	}
+	var last uint64
+	if index := strings.LastIndex(lastEventID, "-"); index != -1 && lastEventID[:index] == hub.epoch {
+		last, _ = strconv.ParseUint(lastEventID[index+1:], 10, 64)
+	}
+	var messages []*Message
+	for _, msg := range hub.history {
+		if msg.seq > last && sub.accept(msg) {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func (hub *Hub) unsubscribe(sub *subscriber) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if _, existed := hub.subscribers[sub]; existed {
+		delete(hub.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (sub *subscriber) accept(msg *Message) bool {
+	if sub.topics != nil && !sub.topics[msg.Topic] {
+		return false
+	}
+	return sub.filter == nil || sub.filter(msg)
+}
+
+func send(stream *webapi.EventStream, msg *Message) error {
+	return stream.Send(webapi.Event{
+		ID:    msg.ID,
+		Event: msg.Event,
+		Data:  msg.payload,
+	})
+}
@@ -0,0 +1,40 @@
+package webapi
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+)
+
+//bodyStream the deserializer and reader of the unread body (the reader is nil if the body is empty),
+//nil deserializer means the body should be buffered (BeforeReading is installed or the deserializer cannot stream)
+func (ctx *Context) bodyStream() (StreamDeserializer, io.Reader) {
+	stream, isStream := ctx.Deserializer.(StreamDeserializer)
+	if !isStream || ctx.BeforeReading != nil || ctx.body != nil || ctx.r.Body == nil {
+		return nil, nil
+	}
+	//the body is consumed by deserializer and cannot be read by Body() anymore
+	ctx.body = []byte{}
+	switch {
+	case ctx.r.ContentLength == 0:
+		return stream, nil
+	case ctx.r.ContentLength < 0:
+		//the length is unknown (e.g. chunked)
+		reader := bufio.NewReader(ctx.r.Body)
+		if _, err := reader.Peek(1); err == io.EOF {
+			return stream, nil
+		}
+		return stream, reader
+	}
+	return stream, ctx.r.Body
+}
+
+//streamError convert the error of reading body in the same way as bindingBody
+func (ctx *Context) streamError(err error) error {
+	if errors.Is(err, ErrBodyTooLarge) {
+		ctx.bodyErr = err
+		return NewHTTPError(http.StatusRequestEntityTooLarge, err)
+	}
+	return err
+}
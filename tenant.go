@@ -0,0 +1,135 @@
+package webapi
+
+import (
+	"net/http"
+	"sort"
+)
+
+type (
+	//routeTable endpoints grouped by http method
+	routeTable map[string]*endpoint
+
+	//tenant Tenant routes overlay and middlewares
+	tenant struct {
+		id          string
+		handlers    routeTable
+		middlewares []Middleware
+	}
+
+	//routeLookup the cached result of dynamic route lookup
+	routeLookup struct {
+		handler interface{}
+		args    []string
+	}
+)
+
+//SetTenantResolver Set the resolver to detect the tenant of request,
+//the error returned by resolver will be replied to client (400 by default)
+func (host *Host) SetTenantResolver(resolver func(*http.Request) (tenantID string, err error)) *Host {
+	host.tenantResolver = resolver
+	return host
+}
+
+//Tenant Register the tenant specific routes in register function,
+//these routes will take precedence over the host routes for the tenant.
+//The middlewares will be applied to all requests of the tenant.
+//Like Group, other goroutines should not register during the register function.
+func (host *Host) Tenant(tenantID string, register func(), middlewares ...Middleware) {
+	{
+		host.mutex.Lock()
+		host.initCheck()
+		if host.tenants == nil {
+			host.tenants = map[string]*tenant{}
+		}
+		orginalScope := host.scope
+		defer func() {
+			host.mutex.Lock()
+			host.scope = orginalScope
+			host.mutex.Unlock()
+		}()
+	}
+	current, existed := host.tenants[tenantID]
+	if !existed {
+		current = &tenant{
+			id:       tenantID,
+			handlers: routeTable{},
+		}
+		host.tenants[tenantID] = current
+	}
+	current.middlewares = append(current.middlewares, middlewares...)
+	host.scope = current
+	//the lock is released for the registrations in scope
+	host.mutex.Unlock()
+	if register != nil {
+		register()
+	}
+}
+
+//Tenant The tenant of current request
+func (ctx *Context) Tenant() string {
+	return ctx.tenant
+}
+
+//currentRoutes the route table which is registering to
+func (host *Host) currentRoutes() routeTable {
+	if host.scope != nil {
+		return host.scope.handlers
+	}
+	return host.handlers
+}
+
+//search find the handler via method and path (keys are the lowered path if required), the arguments are appended to args
+func (table routeTable) search(method string, path string, keys string, args []string) (interface{}, []string) {
+	if collection := table[method]; collection != nil {
+		if handler, matched := collection.Search(path, keys, args); handler != nil {
+			return handler, matched
+		}
+	}
+	return nil, args
+}
+
+//lookup find the handler in the tenant routes and then the host routes, the arguments are appended to args.
+//The results of dynamic routes are cached if RouteCacheSize is set.
+func (host *Host) lookup(tenant *tenant, method string, path string, keys string, args []string) (interface{}, []string) {
+	var key string
+	if host.lookups != nil {
+		if tenant != nil {
+			key = tenant.id
+		}
+		key += "\x00" + method + " " + path
+		if cached, existed := host.lookups.Get(key); existed {
+			lookup := cached.(*routeLookup)
+			return lookup.handler, append(args, lookup.args...)
+		}
+	}
+	var handler interface{}
+	var matched []string
+	if tenant != nil {
+		//tenant routes take precedence over the host routes
+		handler, matched = tenant.handlers.search(method, path, keys, args)
+	}
+	if handler == nil {
+		handler, matched = host.handlers.search(method, path, keys, args)
+	}
+	if host.lookups != nil && handler != nil && len(matched) > len(args) {
+		//only the dynamic routes are cached, the static routes have been found without matching
+		host.lookups.Add(key, &routeLookup{handler: handler, args: append([]string{}, matched[len(args):]...)})
+	}
+	return handler, matched
+}
+
+//allowedMethods find the methods which can handle the path in tables (sorted)
+func allowedMethods(path string, keys string, tables ...routeTable) []string {
+	var methods []string
+	found := map[string]bool{}
+	for _, table := range tables {
+		for method, collection := range table {
+			if handler, _ := collection.Search(path, keys, nil); handler != nil && !found[method] {
+				found[method] = true
+				methods = append(methods, method)
+			}
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
@@ -0,0 +1,92 @@
+package webapi
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+)
+
+type (
+	//TestContextOption Option of NewTestContext
+	TestContextOption func(*Context)
+
+	//testWriter the response writer of test context which records the body written
+	testWriter struct {
+		http.ResponseWriter
+		body bytes.Buffer
+	}
+)
+
+//NewTestContext Create context to unit-test middlewares and helpers without host,
+//the recorder and "GET /" request will be used if w or r is nil,
+//the written data can be read via StatusCode() and ResponseBody()
+func NewTestContext(w http.ResponseWriter, r *http.Request, opts ...TestContextOption) *Context {
+	if w == nil {
+		w = httptest.NewRecorder()
+	}
+	if r == nil {
+		r = httptest.NewRequest(http.MethodGet, "/", nil)
+	}
+	ctx := &Context{
+		w:         &testWriter{ResponseWriter: w},
+		r:         r,
+		mediaType: ParseMediaType(r.Header.Get("Content-Type")),
+	}
+	ctx.Deserializer = Serializers[ctx.mediaType.Type]
+	for _, opt := range opts {
+		opt(ctx)
+	}
+	return ctx
+}
+
+//WithHost The test context uses the serializers, error handler and body transformers of host
+func WithHost(host *Host) TestContextOption {
+	return func(ctx *Context) {
+		ctx.host = host
+		ctx.Deserializer = host.Serializer(ctx.mediaType.Type)
+		ctx.BeforeReading, ctx.BeforeWriting = host.beforeReading, host.beforeWriting
+	}
+}
+
+//WithTenant The test context is resolved as the tenant
+func WithTenant(name string) TestContextOption {
+	return func(ctx *Context) {
+		ctx.tenant = name
+	}
+}
+
+//WithSerializer The serializer which marshals the replies of test context
+func WithSerializer(serializer Serializer) TestContextOption {
+	return func(ctx *Context) {
+		ctx.Serializer = serializer
+	}
+}
+
+//ResponseBody The body written by test context (nil if the context is not created by NewTestContext)
+func (ctx *Context) ResponseBody() []byte {
+	if w, isTest := ctx.w.(*testWriter); isTest {
+		return w.body.Bytes()
+	}
+	return nil
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *testWriter) Flush() {
+	if flusher, isFlusher := w.ResponseWriter.(http.Flusher); isFlusher {
+		flusher.Flush()
+	}
+}
+
+func (w *testWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, isHijacker := w.ResponseWriter.(http.Hijacker); isHijacker {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("the response writer does not support hijacking")
+}
@@ -0,0 +1,39 @@
+package webapi
+
+import (
+	"crypto/tls"
+)
+
+//TLSModern The preset which only accepts TLS 1.3 (the cipher suites of TLS 1.3 are not configurable),
+//it is suitable for services whose clients are all up to date
+func TLSModern() *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS13,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+	}
+}
+
+//TLSIntermediate The preset which accepts TLS 1.2 with the AEAD cipher suites of forward secrecy and TLS 1.3,
+//it is the default of RunTLS
+func TLSIntermediate() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+		},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+	}
+}
+
+//tlsConfig the TLS configuration of RunTLS (the copy of TLSConfig or the intermediate preset)
+func (host *Host) tlsConfig() *tls.Config {
+	if host.conf.TLSConfig != nil {
+		return host.conf.TLSConfig.Clone()
+	}
+	return TLSIntermediate()
+}
@@ -0,0 +1,53 @@
+package webapi
+
+import (
+	"net/http"
+)
+
+type (
+	//trailer the trailer of response evaluated after the handler finished
+	trailer struct {
+		name     string
+		value    func() string
+		declared bool
+	}
+)
+
+//SetTrailer Set the trailer of response (e.g. checksum or count of streamed rows), the value is evaluated
+//after the handler finished. It is declared by Trailer header if the header has not been written yet,
+//otherwise it is still sent by HTTP/1.1 chunked and HTTP/2 responses but clients might not expect it.
+func (ctx *Context) SetTrailer(name string, value func() string) {
+	name = http.CanonicalHeaderKey(name)
+	for index := range ctx.trailers {
+		if ctx.trailers[index].name == name {
+			ctx.trailers[index].value = value
+			return
+		}
+	}
+	ctx.trailers = append(ctx.trailers, trailer{name: name, value: value})
+}
+
+//declareTrailers declare the trailers by Trailer header before the header is written
+func (ctx *Context) declareTrailers() {
+	for index := range ctx.trailers {
+		if !ctx.trailers[index].declared {
+			ctx.trailers[index].declared = true
+			ctx.w.Header().Add("Trailer", ctx.trailers[index].name)
+		}
+	}
+}
+
+//writeTrailers evaluate the trailers into the header (the undeclared ones are set with http.TrailerPrefix)
+func (ctx *Context) writeTrailers() {
+	if len(ctx.trailers) == 0 || ctx.statuscode == http.StatusSwitchingProtocols {
+		return
+	}
+	header := ctx.w.Header()
+	for _, trailer := range ctx.trailers {
+		if trailer.declared {
+			header.Set(trailer.name, trailer.value())
+		} else {
+			header.Set(http.TrailerPrefix+trailer.name, trailer.value())
+		}
+	}
+}
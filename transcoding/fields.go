@@ -0,0 +1,109 @@
+package transcoding
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//findField find the field of message via field path (e.g. a.b), the nil messages on path will be created if required
+func findField(value reflect.Value, path string, create bool) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, ".") {
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				if !create || !value.CanSet() {
+					return value, false
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return value, false
+		}
+		found := false
+		for index := 0; index < value.NumField(); index++ {
+			if field := value.Type().Field(index); len(field.PkgPath) == 0 && matchField(field, name) {
+				value, found = value.Field(index), true
+				break
+			}
+		}
+		if !found {
+			return value, false
+		}
+	}
+	return value, true
+}
+
+//matchField the field matches the proto name, json name or go name
+func matchField(field reflect.StructField, name string) bool {
+	for _, option := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if option == "name="+name || option == "json="+name {
+			return true
+		}
+	}
+	if jsonname := strings.Split(field.Tag.Get("json"), ",")[0]; jsonname == name {
+		return true
+	}
+	return strings.EqualFold(field.Name, strings.Replace(name, "_", "", -1))
+}
+
+//setField assign text to the field of message, the value will be appended if the field is repeated
+func setField(value reflect.Value, path string, text string) error {
+	field, found := findField(value, path, true)
+	if !found {
+		return errors.New("field " + path + " is not found")
+	}
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		item := reflect.New(field.Type().Elem()).Elem()
+		if err := setScalar(item, text); err != nil {
+			return errors.New("invalid value for " + path + ": " + err.Error())
+		}
+		field.Set(reflect.Append(field, item))
+		return nil
+	}
+	if err := setScalar(field, text); err != nil {
+		return errors.New("invalid value for " + path + ": " + err.Error())
+	}
+	return nil
+}
+
+//setScalar convert text into the value
+func setScalar(value reflect.Value, text string) (err error) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return setScalar(value.Elem(), text)
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		var v bool
+		if v, err = strconv.ParseBool(text); err == nil {
+			value.SetBool(v)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var v int64
+		if v, err = strconv.ParseInt(text, 10, value.Type().Bits()); err == nil {
+			value.SetInt(v)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var v uint64
+		if v, err = strconv.ParseUint(text, 10, value.Type().Bits()); err == nil {
+			value.SetUint(v)
+		}
+	case reflect.Float32, reflect.Float64:
+		var v float64
+		if v, err = strconv.ParseFloat(text, value.Type().Bits()); err == nil {
+			value.SetFloat(v)
+		}
+	case reflect.Slice:
+		//bytes
+		value.SetBytes([]byte(text))
+	default:
+		err = errors.New("unsupported type " + value.Type().String())
+	}
+	return
+}
@@ -0,0 +1,83 @@
+package transcoding
+
+import (
+	"net/http"
+	"strconv"
+)
+
+//Code gRPC status code
+type Code int
+
+//gRPC status codes
+const (
+	OK Code = iota
+	Canceled
+	Unknown
+	InvalidArgument
+	DeadlineExceeded
+	NotFound
+	AlreadyExists
+	PermissionDenied
+	ResourceExhausted
+	FailedPrecondition
+	Aborted
+	OutOfRange
+	Unimplemented
+	Internal
+	Unavailable
+	DataLoss
+	Unauthenticated
+)
+
+var (
+	//httpStatus HTTP status of gRPC code (the mapping of google.rpc.Code)
+	httpStatus = map[Code]int{
+		OK:                 http.StatusOK,
+		Canceled:           499,
+		Unknown:            http.StatusInternalServerError,
+		InvalidArgument:    http.StatusBadRequest,
+		DeadlineExceeded:   http.StatusGatewayTimeout,
+		NotFound:           http.StatusNotFound,
+		AlreadyExists:      http.StatusConflict,
+		PermissionDenied:   http.StatusForbidden,
+		ResourceExhausted:  http.StatusTooManyRequests,
+		FailedPrecondition: http.StatusBadRequest,
+		Aborted:            http.StatusConflict,
+		OutOfRange:         http.StatusBadRequest,
+		Unimplemented:      http.StatusNotImplemented,
+		Internal:           http.StatusInternalServerError,
+		Unavailable:        http.StatusServiceUnavailable,
+		DataLoss:           http.StatusInternalServerError,
+		Unauthenticated:    http.StatusUnauthorized,
+	}
+)
+
+type (
+	//Error Error with gRPC status code
+	Error struct {
+		Code    Code          `json:"code"`
+		Message string        `json:"message"`
+		Details []interface{} `json:"details"`
+	}
+)
+
+//Errorf Create error with gRPC status code
+func Errorf(code Code, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+		Details: []interface{}{},
+	}
+}
+
+func (err *Error) Error() string {
+	return "rpc error: code = " + strconv.Itoa(int(err.Code)) + " desc = " + err.Message
+}
+
+//HTTPStatus The HTTP status code of gRPC code
+func (code Code) HTTPStatus() int {
+	if status, existed := httpStatus[code]; existed {
+		return status
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,369 @@
+package transcoding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-webapi/webapi"
+)
+
+type (
+	//Rule HTTP rule of method (the same as google.api.http annotation)
+	Rule struct {
+		//Method HTTP method, e.g. GET
+		Method string
+
+		//Pattern URL pattern, e.g. /v1/{name=messages/*}:cancel ("**" is not supported)
+		Pattern string
+
+		//Body The request field mapped to body, "*" means the whole request and empty means no body
+		Body string
+
+		//ResponseBody The response field mapped to body, empty means the whole response
+		ResponseBody string
+	}
+
+	//Method Unary gRPC method bound to HTTP rule
+	Method struct {
+		//Name Full name of method, e.g. /pkg.Service/Method
+		Name string
+
+		//Rule HTTP rule
+		Rule Rule
+
+		//NewRequest Create an empty request message
+		NewRequest func() interface{}
+
+		//Handler Invoke the method
+		Handler func(ctx context.Context, req interface{}) (interface{}, error)
+	}
+
+	//Codec Convert messages between JSON and message (e.g. protojson)
+	Codec interface {
+		Marshal(msg interface{}) ([]byte, error)
+		Unmarshal(data []byte, msg interface{}) error
+	}
+
+	//Options Options of transcoding
+	Options struct {
+		//Codec The JSON codec of messages, default is encoding/json
+		Codec Codec
+
+		//StatusOf Get status from error returned by handler (e.g. status.FromError of grpc),
+		//*Error will be recognized and Unknown will be used for other errors by default
+		StatusOf func(error) (Code, string)
+	}
+
+	//binding compiled rule
+	binding struct {
+		method    Method
+		route     string
+		variables map[string][]int //field path -> segment indexes
+		segments  int              //the count of segments, the indexes are relative to the end of path (base paths are skipped)
+		verb      string
+		options   Options
+	}
+
+	//dispatcher the bindings sharing the same route, selected by the custom verb of the last segment
+	dispatcher struct {
+		method   string
+		route    string
+		bindings []*binding
+	}
+
+	jsonCodec struct{}
+
+	contextKey struct{}
+)
+
+//Register Register routes of methods with the host
+func Register(host *webapi.Host, methods []Method, opts Options, middlewares ...webapi.Middleware) error {
+	if opts.Codec == nil {
+		opts.Codec = jsonCodec{}
+	}
+	if opts.StatusOf == nil {
+		opts.StatusOf = statusOf
+	}
+	var dispatchers []*dispatcher
+	routes := map[string]*dispatcher{}
+	for _, method := range methods {
+		b, err := compile(method, opts)
+		if err != nil {
+			return err
+		}
+		httpMethod := strings.ToUpper(method.Rule.Method)
+		d := routes[httpMethod+" "+b.route]
+		if d == nil {
+			d = &dispatcher{method: httpMethod, route: b.route}
+			routes[httpMethod+" "+b.route] = d
+			dispatchers = append(dispatchers, d)
+		}
+		for _, existed := range d.bindings {
+			if existed.verb == b.verb {
+				return errors.New("the rules of " + existed.method.Name + " and " + method.Name + " are conflicted on " + httpMethod + " " + b.route)
+			}
+		}
+		d.bindings = append(d.bindings, b)
+	}
+	for _, d := range dispatchers {
+		if err := host.AddEndpoint(d.method, d.route, d.serve, middlewares...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Service Bind the methods of gRPC service implementation via rules (method name -> rule),
+//the methods should be declared as func(context.Context, *Request) (*Response, error)
+func Service(name string, impl interface{}, rules map[string]Rule) ([]Method, error) {
+	var methods []Method
+	value := reflect.ValueOf(impl)
+	names := make([]string, 0, len(rules))
+	for methodname := range rules {
+		names = append(names, methodname)
+	}
+	//the routes are registered in a stable order
+	sort.Strings(names)
+	for _, methodname := range names {
+		rule := rules[methodname]
+		function := value.MethodByName(methodname)
+		if !function.IsValid() {
+			return nil, errors.New("method " + methodname + " is not found in " + value.Type().String())
+		}
+		typ := function.Type()
+		if typ.NumIn() != 2 || typ.NumOut() != 2 || typ.In(1).Kind() != reflect.Ptr || !typ.Out(1).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+			return nil, errors.New("method " + methodname + " is not an unary gRPC method")
+		}
+		reqType := typ.In(1).Elem()
+		methods = append(methods, Method{
+			Name: "/" + name + "/" + methodname,
+			Rule: rule,
+			NewRequest: func() interface{} {
+				return reflect.New(reqType).Interface()
+			},
+			Handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				results := function.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(req)})
+				if err, _ := results[1].Interface().(error); err != nil {
+					return nil, err
+				}
+				return results[0].Interface(), nil
+			},
+		})
+	}
+	return methods, nil
+}
+
+//FromContext Get webapi context from the context of handler
+func FromContext(ctx context.Context) *webapi.Context {
+	webctx, _ := ctx.Value(contextKey{}).(*webapi.Context)
+	return webctx
+}
+
+//compile parse the pattern into route
+func compile(method Method, opts Options) (*binding, error) {
+	pattern := method.Rule.Pattern
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, errors.New("invalid pattern " + pattern)
+	}
+	b := &binding{
+		method:    method,
+		variables: map[string][]int{},
+		options:   opts,
+	}
+	if index := strings.LastIndex(pattern, ":"); index > strings.LastIndex(pattern, "}") && index > strings.LastIndex(pattern, "/") {
+		pattern, b.verb = pattern[:index], pattern[index+1:]
+	}
+	var segments []string
+	for _, part := range splitPattern(pattern[1:]) {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			field, template := part[1:len(part)-1], "*"
+			if index := strings.Index(field, "="); index != -1 {
+				field, template = field[:index], field[index+1:]
+			}
+			for _, sub := range strings.Split(template, "/") {
+				if sub == "**" || strings.ContainsAny(sub, "{}") {
+					return nil, errors.New("unsupported pattern " + method.Rule.Pattern)
+				}
+				if sub == "*" {
+					sub = "{string}"
+				}
+				b.variables[field] = append(b.variables[field], len(segments))
+				segments = append(segments, sub)
+			}
+			continue
+		}
+		if part == "**" || strings.ContainsAny(part, "{}") {
+			return nil, errors.New("unsupported pattern " + method.Rule.Pattern)
+		}
+		if part == "*" {
+			part = "{string}"
+		}
+		segments = append(segments, part)
+	}
+	if last := len(segments) - 1; len(b.verb) > 0 && last >= 0 && segments[last] != "{string}" {
+		//the verb of variable segment will be checked while serving
+		segments[last] += ":" + b.verb
+	}
+	b.route, b.segments = "/"+strings.Join(segments, "/"), len(segments)
+	return b, nil
+}
+
+//splitPattern split pattern by slash outside the braces
+func splitPattern(pattern string) (parts []string) {
+	var depth, start int
+	for index, r := range pattern {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				parts = append(parts, pattern[start:index])
+				start = index + 1
+			}
+		}
+	}
+	return append(parts, pattern[start:])
+}
+
+//serve select the binding by the verb of path, the binding without verb is used if none of verbs is matched
+func (d *dispatcher) serve(ctx *webapi.Context) {
+	var fallback *binding
+	for _, b := range d.bindings {
+		if len(b.verb) == 0 {
+			fallback = b
+		} else if strings.HasSuffix(ctx.GetRequest().URL.Path, ":"+b.verb) {
+			b.serve(ctx)
+			return
+		}
+	}
+	if fallback == nil {
+		ctx.ReplyError(http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+		return
+	}
+	fallback.serve(ctx)
+}
+
+//serve transcode request into method and response back
+func (b *binding) serve(ctx *webapi.Context) {
+	req := b.method.NewRequest()
+	if err := b.bind(ctx, req); err != nil {
+		b.fail(ctx, Errorf(InvalidArgument, err.Error()))
+		return
+	}
+	resp, err := b.method.Handler(context.WithValue(ctx.GetRequest().Context(), contextKey{}, ctx), req)
+	if err != nil {
+		b.fail(ctx, err)
+		return
+	}
+	var body interface{} = resp
+	if len(b.method.Rule.ResponseBody) > 0 {
+		if field, found := findField(reflect.ValueOf(resp), b.method.Rule.ResponseBody, false); found {
+			body = field.Interface()
+		}
+	}
+	data, err := b.options.Codec.Marshal(body)
+	if err != nil {
+		b.fail(ctx, Errorf(Internal, err.Error()))
+		return
+	}
+	ctx.ResponseHeader().Set("Content-Type", "application/json")
+	ctx.Write(http.StatusOK, data)
+}
+
+//bind assign body, path variables and query into request message
+func (b *binding) bind(ctx *webapi.Context, req interface{}) error {
+	value, data := reflect.ValueOf(req), []byte(nil)
+	if len(b.method.Rule.Body) > 0 {
+		if data = ctx.Body(); ctx.BodyError() != nil {
+			return ctx.BodyError()
+		}
+		if ctx.BeforeReading != nil {
+			data = ctx.BeforeReading(data)
+		}
+	}
+	switch body := b.method.Rule.Body; {
+	case body == "*":
+		if len(data) > 0 {
+			if err := b.options.Codec.Unmarshal(data, req); err != nil {
+				return err
+			}
+		}
+	case len(body) > 0:
+		if len(data) > 0 {
+			field, found := findField(value, body, true)
+			if !found {
+				return errors.New("field " + body + " is not found")
+			}
+			if field.Kind() != reflect.Ptr {
+				field = field.Addr()
+			} else if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			if err := b.options.Codec.Unmarshal(data, field.Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	segments := strings.Split(ctx.GetRequest().URL.Path, "/")[1:]
+	if last := len(segments) - 1; len(b.verb) > 0 && last >= 0 {
+		segments[last] = strings.TrimSuffix(segments[last], ":"+b.verb)
+	}
+	//the base path of group is skipped
+	offset := len(segments) - b.segments
+	for path, indexes := range b.variables {
+		var parts []string
+		for _, index := range indexes {
+			if index += offset; index >= 0 && index < len(segments) {
+				parts = append(parts, segments[index])
+			}
+		}
+		if err := setField(value, path, strings.Join(parts, "/")); err != nil {
+			return err
+		}
+	}
+	if b.method.Rule.Body != "*" {
+		for path, values := range ctx.GetRequest().URL.Query() {
+			if _, isVariable := b.variables[path]; isVariable || path == b.method.Rule.Body {
+				continue
+			}
+			for _, v := range values {
+				if err := setField(value, path, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+//fail reply the error as google.rpc.Status
+func (b *binding) fail(ctx *webapi.Context, err error) {
+	code, message := b.options.StatusOf(err)
+	data, _ := json.Marshal(Errorf(code, message))
+	ctx.ResponseHeader().Set("Content-Type", "application/json")
+	ctx.Write(code.HTTPStatus(), data)
+}
+
+//statusOf default status extractor
+func statusOf(err error) (Code, string) {
+	var status *Error
+	if errors.As(err, &status) {
+		return status.Code, status.Message
+	}
+	return Unknown, err.Error()
+}
+
+func (jsonCodec) Marshal(msg interface{}) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+func (jsonCodec) Unmarshal(data []byte, msg interface{}) error {
+	return json.Unmarshal(data, msg)
+}
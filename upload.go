@@ -0,0 +1,216 @@
+package webapi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+var (
+	//ErrUploadTooLarge The uploaded file or the total uploads exceed the limitation of UploadOptions (413)
+	ErrUploadTooLarge = errors.New("the upload is too large")
+
+	//ErrUploadType The sniffed content type of the uploaded file is not allowed (415)
+	ErrUploadType = errors.New("the type of upload is not allowed")
+)
+
+//sniffLength the bytes used to detect content type (the same as http.DetectContentType)
+const sniffLength = 512
+
+type (
+	//UploadOptions The limitations of uploads, the zero value means no limitation (the body is still limited by MaxBodySize)
+	UploadOptions struct {
+		//MaxFileSize The maximum bytes of each file
+		MaxFileSize int64
+		//MaxTotalSize The maximum bytes of all files and fields
+		MaxTotalSize int64
+		//AllowedTypes The allowed content types detected from contents (e.g. image/png or image/*)
+		AllowedTypes []string
+		//Store The storage of files, default is the temp files of system
+		Store BlobStore
+		//Progress Will be invoked with the bytes of file written so far
+		Progress func(file *UploadedFile, written int64)
+	}
+
+	//BlobStore The storage of uploaded files
+	BlobStore interface {
+		//Put Save the content and return the key of it
+		Put(name string, content io.Reader) (key string, err error)
+		//Open Open the content by key
+		Open(key string) (io.ReadCloser, error)
+		//Delete Delete the content by key
+		Delete(key string) error
+	}
+
+	//UploadedFile The file stored by ctx.Uploads
+	UploadedFile struct {
+		//Field The name of form field
+		Field string
+		//Filename The name of file provided by client (the directories are removed)
+		Filename string
+		//ContentType The content type detected from content
+		ContentType string
+		//Size The bytes of file
+		Size int64
+		//Key The key of file in store (the path of temp file by default)
+		Key string
+
+		store BlobStore
+		kept  bool
+	}
+
+	//tempStore the store of temp files
+	tempStore struct {
+		dir string
+	}
+
+	//uploadReader the reader which counts the bytes of file and total
+	uploadReader struct {
+		io.Reader
+		file    *UploadedFile
+		total   *int64
+		options *UploadOptions
+	}
+)
+
+//NewTempStore Create the store which saves files into dir (the default temp directory if empty)
+func NewTempStore(dir string) BlobStore {
+	return &tempStore{dir: dir}
+}
+
+func (store *tempStore) Put(name string, content io.Reader) (string, error) {
+	file, err := ioutil.TempFile(store.dir, "webapi-upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err = io.Copy(file, content); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+func (store *tempStore) Open(key string) (io.ReadCloser, error) {
+	return os.Open(key)
+}
+
+func (store *tempStore) Delete(key string) error {
+	return os.Remove(key)
+}
+
+//Uploads Stream the files of multipart request into the store one by one, the values of other fields are returned as form.
+//The stored files will be deleted after the request is finished unless they are kept.
+func (ctx *Context) Uploads(options UploadOptions) ([]*UploadedFile, url.Values, error) {
+	if options.Store == nil {
+		options.Store = NewTempStore("")
+	}
+	reader, err := ctx.r.MultipartReader()
+	if err == http.ErrNotMultipart {
+		return nil, nil, NewHTTPError(http.StatusUnsupportedMediaType, err)
+	} else if err != nil {
+		return nil, nil, NewHTTPError(http.StatusBadRequest, err)
+	}
+	var files []*UploadedFile
+	var form = url.Values{}
+	var total int64
+	ctx.OnFinish(func(*Context) {
+		for _, file := range files {
+			if !file.kept {
+				file.store.Delete(file.Key)
+			}
+		}
+	})
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return files, form, nil
+		} else if err != nil {
+			return files, form, ctx.uploadError(err)
+		}
+		file := &UploadedFile{Field: part.FormName(), Filename: part.FileName(), store: options.Store}
+		content := &uploadReader{Reader: part, file: file, total: &total, options: &options}
+		if len(file.Filename) == 0 {
+			//the value of field is limited by total size only
+			content.file = nil
+			value, err := ioutil.ReadAll(content)
+			if err != nil {
+				return files, form, ctx.uploadError(err)
+			}
+			form.Add(file.Field, string(value))
+			continue
+		}
+		head := make([]byte, sniffLength)
+		n, err := io.ReadFull(content, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return files, form, ctx.uploadError(err)
+		}
+		file.ContentType = http.DetectContentType(head[:n])
+		if !allowedUpload(file.ContentType, options.AllowedTypes) {
+			return files, form, NewHTTPError(http.StatusUnsupportedMediaType, ErrUploadType)
+		}
+		key, err := options.Store.Put(file.Filename, io.MultiReader(bytes.NewReader(head[:n]), content))
+		if err != nil {
+			return files, form, ctx.uploadError(err)
+		}
+		file.Key = key
+		files = append(files, file)
+	}
+}
+
+//uploadError convert the error of reading uploads
+func (ctx *Context) uploadError(err error) error {
+	if errors.Is(err, ErrUploadTooLarge) {
+		return NewHTTPError(http.StatusRequestEntityTooLarge, ErrUploadTooLarge)
+	}
+	if errors.Is(err, ErrBodyTooLarge) {
+		return ctx.streamError(ErrBodyTooLarge)
+	}
+	return err
+}
+
+//allowedUpload whether the content type matches any of allowed types (all types are allowed if empty)
+func allowedUpload(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	contentType = ParseMediaType(contentType).Type
+	for _, typ := range allowed {
+		if ParseMediaType(typ).Matches(contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *uploadReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	*r.total += int64(n)
+	if r.options.MaxTotalSize > 0 && *r.total > r.options.MaxTotalSize {
+		return n, ErrUploadTooLarge
+	}
+	if r.file != nil {
+		r.file.Size += int64(n)
+		if r.options.MaxFileSize > 0 && r.file.Size > r.options.MaxFileSize {
+			return n, ErrUploadTooLarge
+		}
+		if r.options.Progress != nil && n > 0 {
+			r.options.Progress(r.file, r.file.Size)
+		}
+	}
+	return
+}
+
+//Open Open the content of file from store
+func (file *UploadedFile) Open() (io.ReadCloser, error) {
+	return file.store.Open(file.Key)
+}
+
+//Keep Keep the file in store after the request is finished (e.g. it has been moved or persisted)
+func (file *UploadedFile) Keep() {
+	file.kept = true
+}
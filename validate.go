@@ -0,0 +1,259 @@
+package webapi
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const (
+	//SeverityError The controller or route cannot work as expected
+	SeverityError = "error"
+	//SeverityWarning The controller or route works but might be unexpected
+	SeverityWarning = "warning"
+)
+
+var (
+	//queryKinds the element kinds which can be assigned from query by setValue
+	queryKinds = map[reflect.Kind]bool{
+		reflect.String: true, reflect.Bool: true,
+		reflect.Int: true, reflect.Int16: true, reflect.Int32: true, reflect.Int64: true,
+		reflect.Uint: true, reflect.Uint8: true, reflect.Uint16: true, reflect.Uint32: true, reflect.Uint64: true,
+		reflect.Float32: true, reflect.Float64: true,
+	}
+)
+
+type (
+	//Diagnostic The diagnostic reported by host.Validate
+	Diagnostic struct {
+		Severity   string
+		Code       string
+		Controller string `json:",omitempty"`
+		Action     string `json:",omitempty"`
+		Route      string `json:",omitempty"`
+		Message    string
+	}
+)
+
+//Validate Inspect the registered controllers and routes, the problems are reported as diagnostics:
+//registration errors, unbindable parameters, body and query conflicts, unbindable fields of request structures,
+//Check methods with wrong signatures, placeholder and argument count mismatches and unreachable routes
+func (host *Host) Validate() []Diagnostic {
+	//the registration might be running concurrently, inspect the snapshots
+	host.mutex.Lock()
+	host.initCheck()
+	errs := append([]error{}, host.errList...)
+	controllers := append([]Controller{}, host.controllers...)
+	host.mutex.Unlock()
+	diagnostics := []Diagnostic{}
+	for _, err := range errs {
+		diagnostics = append(diagnostics, Diagnostic{Severity: SeverityError, Code: "registration", Message: err.Error()})
+	}
+	for _, controller := range controllers {
+		diagnostics = append(diagnostics, host.validateController(controller)...)
+	}
+	return append(diagnostics, host.validateRoutes(host.registeredRoutes())...)
+}
+
+func (diagnostic Diagnostic) String() string {
+	text := "[" + diagnostic.Severity + "] " + diagnostic.Code + ": "
+	if len(diagnostic.Route) > 0 {
+		text += diagnostic.Route + ": "
+	} else if len(diagnostic.Controller) > 0 {
+		text += diagnostic.Controller + "." + diagnostic.Action + ": "
+	}
+	return text + diagnostic.Message
+}
+
+//validateController inspect the controller in the same way as Register
+func (host *Host) validateController(controller Controller) (diagnostics []Diagnostic) {
+	typ := reflect.TypeOf(controller)
+	name := controllerName(typ)
+	report := func(severity, code, action, message string) {
+		diagnostics = append(diagnostics, Diagnostic{Severity: severity, Code: code, Controller: name, Action: action, Message: message})
+	}
+	var contextArgs []reflect.Type
+	if init, existed := typ.MethodByName("Init"); existed {
+		if init.Type.NumOut() != 1 || init.Type.Out(0) != types.Error {
+			report(SeverityWarning, "init-signature", "Init", "Init should return error only, it is registered as action now")
+		} else {
+			contextArgs = []reflect.Type{}
+			for index := 1; index < init.Type.NumIn(); index++ {
+				arg := init.Type.In(index)
+				if _, err := getReplacer(arg); err != nil {
+					report(SeverityError, "unbindable-param", "Init", "the parameter "+arg.String()+" cannot be bound from path")
+				}
+				contextArgs = append(contextArgs, arg)
+			}
+		}
+	}
+	_, semantics := host.getBasePath(controller)
+	for index := 0; index < typ.NumMethod(); index++ {
+		method := typ.Method(index)
+		if internalControllerMethods[method.Name] || (method.Name == "Init" && contextArgs != nil) {
+			continue
+		}
+		var body, query []reflect.Type
+		var valid = true
+		for argindex := 1; argindex < method.Type.NumIn(); argindex++ {
+			arg := method.Type.In(argindex)
+			switch {
+			case bodyTypes[arg.Kind()]:
+				body = append(body, arg)
+			case arg.Kind() == reflect.Struct:
+				query = append(query, arg)
+			default:
+				if _, err := getReplacer(arg); err != nil {
+					valid = false
+					report(SeverityError, "unbindable-param", method.Name, "the parameter "+arg.String()+" cannot be bound from path")
+				}
+			}
+		}
+		if len(body) > 1 {
+			valid = false
+			report(SeverityError, "body-conflict", method.Name, "only one parameter can be bound from body")
+		}
+		if len(query) > 1 {
+			valid = false
+			report(SeverityError, "query-conflict", method.Name, "only one parameter can be bound from query")
+		}
+		for _, arg := range body {
+			for _, message := range requestStructIssues(arg, false) {
+				report(SeverityWarning, message[0], method.Name, message[1])
+			}
+		}
+		for _, arg := range query {
+			for _, message := range requestStructIssues(arg, true) {
+				report(SeverityWarning, message[0], method.Name, message[1])
+			}
+		}
+		if len(body) == 1 && len(query) == 1 {
+			for _, field := range ambiguousFields(body[0], query[0]) {
+				report(SeverityWarning, "ambiguous-field", method.Name, "the field "+field+" is declared in both body and query")
+			}
+		}
+		if !valid {
+			continue
+		}
+		ep, options, appendix, err := host.getMethodArguments(method, contextArgs, semantics)
+		if err != nil {
+			continue
+		}
+		placeholder := "{" + host.conf.CustomisedPlaceholder + "}"
+		for option, paths := range options {
+			if ep.hasBody() && (option == http.MethodGet || option == http.MethodHead) {
+				report(SeverityWarning, "body-ignored", method.Name, "the body of "+option+" requests is usually dropped by clients and proxies")
+			}
+			for _, path := range paths {
+				if count := strings.Count(path, placeholder); count > len(appendix) {
+					report(SeverityError, "placeholder-mismatch", method.Name, "the path "+path+" declares more placeholders than the path parameters")
+				} else if count > 0 && count < len(appendix) {
+					report(SeverityWarning, "placeholder-mismatch", method.Name, "the path "+path+" declares less placeholders than the path parameters, the rest will be appended")
+				}
+			}
+		}
+	}
+	return
+}
+
+//validateRoutes check whether the routes can be matched by their example paths
+func (host *Host) validateRoutes(routes []RouteInfo) (diagnostics []Diagnostic) {
+	for _, route := range routes {
+		if len(route.Tenant) > 0 {
+			continue
+		}
+		segments := strings.Split(route.Path, "/")
+		for index, segment := range segments {
+			if value, isPlaceholder := placeholderExamples[segment]; isPlaceholder {
+				segments[index] = value
+			}
+		}
+		path := strings.Join(segments, "/")
+		trace := host.Explain(route.Method, path)
+		if !trace.Matched || !strings.EqualFold(trace.Pattern, route.Path) {
+			message := "the example path " + path + " cannot be matched"
+			if trace.Matched {
+				message = "the example path " + path + " is matched by " + trace.Pattern
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity:   SeverityWarning,
+				Code:       "unreachable",
+				Controller: route.Controller,
+				Action:     route.Action,
+				Route:      route.Method + " " + route.Path,
+				Message:    message,
+			})
+		}
+	}
+	return
+}
+
+func (method *function) hasBody() bool {
+	for _, arg := range method.Args {
+		if arg.isBody {
+			return true
+		}
+	}
+	return false
+}
+
+//requestStructIssues find the fields which cannot be bound (code and message pairs)
+func requestStructIssues(typ reflect.Type, isQuery bool) (issues [][2]string) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+	return structIssues(typ, typ.Name(), isQuery)
+}
+
+func structIssues(typ reflect.Type, owner string, isQuery bool) (issues [][2]string) {
+	if check, existed := reflect.PtrTo(typ).MethodByName("Check"); existed {
+		if check.Type.NumIn() != 1 || check.Type.NumOut() != 1 || check.Type.Out(0) != types.Error {
+			issues = append(issues, [2]string{"check-signature", "the method " + owner + ".Check should be func() error, it is ignored now"})
+		}
+	}
+	for index := 0; index < typ.NumField(); index++ {
+		field := typ.Field(index)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			if !field.Anonymous {
+				issues = append(issues, [2]string{"unexported-field", "the field " + owner + "." + field.Name + " is unexported and cannot be bound"})
+			}
+			continue
+		}
+		if !isQuery {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			issues = append(issues, structIssues(field.Type, owner+"."+field.Name, isQuery)...)
+			continue
+		}
+		elem := field.Type
+		for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+			elem = elem.Elem()
+		}
+		if !queryKinds[elem.Kind()] {
+			issues = append(issues, [2]string{"unbindable-field", "the field " + owner + "." + field.Name + " (" + field.Type.String() + ") cannot be bound from query"})
+		}
+	}
+	return
+}
+
+//ambiguousFields the field names declared by both structures
+func ambiguousFields(body reflect.Type, query reflect.Type) (names []string) {
+	declared := map[string]bool{}
+	for _, field := range queryFields(body) {
+		declared[strings.ToLower(field.Name)] = true
+	}
+	for _, field := range queryFields(query) {
+		if declared[strings.ToLower(field.Name)] {
+			names = append(names, field.Name)
+		}
+	}
+	return
+}
@@ -0,0 +1,255 @@
+package webapitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type (
+	//Client In-memory client which serves requests via ServeHTTP (e.g. webapi.Host),
+	//the cookies replied will be kept and sent with the following requests
+	Client struct {
+		handler http.Handler
+		header  http.Header
+		jar     http.CookieJar
+	}
+
+	//Request Request builder
+	Request struct {
+		client *Client
+		method string
+		path   string
+		header http.Header
+		query  url.Values
+		body   io.Reader
+		ctx    context.Context
+		err    error
+	}
+
+	//Expectation Assertions of response, the failures are reported via t.Errorf
+	Expectation struct {
+		t        testing.TB
+		recorder *httptest.ResponseRecorder
+	}
+)
+
+//origin the fake origin of in-memory requests
+var origin, _ = url.Parse("http://example.com")
+
+//NewClient Create the in-memory client of handler
+func NewClient(handler http.Handler) *Client {
+	jar, _ := cookiejar.New(nil)
+	return &Client{
+		handler: handler,
+		header:  http.Header{},
+		jar:     jar,
+	}
+}
+
+//WithHeader Set the default header of all requests (e.g. Authorization)
+func (client *Client) WithHeader(name string, value string) *Client {
+	client.header.Set(name, value)
+	return client
+}
+
+//Get Create GET request
+func (client *Client) Get(path string) *Request {
+	return client.Request(http.MethodGet, path)
+}
+
+//Post Create POST request
+func (client *Client) Post(path string) *Request {
+	return client.Request(http.MethodPost, path)
+}
+
+//Put Create PUT request
+func (client *Client) Put(path string) *Request {
+	return client.Request(http.MethodPut, path)
+}
+
+//Patch Create PATCH request
+func (client *Client) Patch(path string) *Request {
+	return client.Request(http.MethodPatch, path)
+}
+
+//Delete Create DELETE request
+func (client *Client) Delete(path string) *Request {
+	return client.Request(http.MethodDelete, path)
+}
+
+//Head Create HEAD request
+func (client *Client) Head(path string) *Request {
+	return client.Request(http.MethodHead, path)
+}
+
+//Options Create OPTIONS request
+func (client *Client) Options(path string) *Request {
+	return client.Request(http.MethodOptions, path)
+}
+
+//Request Create request with method
+func (client *Client) Request(method string, path string) *Request {
+	header := http.Header{}
+	for name, values := range client.header {
+		header[name] = append([]string{}, values...)
+	}
+	return &Request{
+		client: client,
+		method: method,
+		path:   path,
+		header: header,
+		query:  url.Values{},
+	}
+}
+
+//WithHeader Set the header of request
+func (req *Request) WithHeader(name string, value string) *Request {
+	req.header.Set(name, value)
+	return req
+}
+
+//WithQuery Add the query value of request
+func (req *Request) WithQuery(name string, value string) *Request {
+	req.query.Add(name, value)
+	return req
+}
+
+//WithCookie Add the cookie of request
+func (req *Request) WithCookie(cookie *http.Cookie) *Request {
+	req.header.Add("Cookie", cookie.String())
+	return req
+}
+
+//WithContext Set the context of request
+func (req *Request) WithContext(ctx context.Context) *Request {
+	req.ctx = ctx
+	return req
+}
+
+//WithBody Set the raw body ([]byte, string or io.Reader) with content type
+func (req *Request) WithBody(contentType string, body interface{}) *Request {
+	switch value := body.(type) {
+	case []byte:
+		req.body = bytes.NewReader(value)
+	case string:
+		req.body = strings.NewReader(value)
+	case io.Reader:
+		req.body = value
+	}
+	if len(contentType) > 0 {
+		req.header.Set("Content-Type", contentType)
+	}
+	return req
+}
+
+//WithJSON Set the JSON body
+func (req *Request) WithJSON(body interface{}) *Request {
+	data, err := json.Marshal(body)
+	if err != nil {
+		req.err = err
+	}
+	return req.WithBody("application/json", data)
+}
+
+//WithForm Set the form body
+func (req *Request) WithForm(form url.Values) *Request {
+	return req.WithBody("application/x-www-form-urlencoded", form.Encode())
+}
+
+//Send Serve the request and return the recorded response
+func (req *Request) Send() *httptest.ResponseRecorder {
+	target := req.path
+	if len(req.query) > 0 {
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + req.query.Encode()
+	}
+	r := httptest.NewRequest(req.method, origin.String()+target, req.body)
+	if req.ctx != nil {
+		r = r.WithContext(req.ctx)
+	}
+	for name, values := range req.header {
+		r.Header[name] = values
+	}
+	for _, cookie := range req.client.jar.Cookies(r.URL) {
+		r.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	req.client.handler.ServeHTTP(recorder, r)
+	if cookies := recorder.Result().Cookies(); len(cookies) > 0 {
+		req.client.jar.SetCookies(r.URL, cookies)
+	}
+	return recorder
+}
+
+//Expect Serve the request and assert the response
+func (req *Request) Expect(t testing.TB) *Expectation {
+	t.Helper()
+	if req.err != nil {
+		t.Fatalf("%s %s: %v", req.method, req.path, req.err)
+	}
+	return &Expectation{
+		t:        t,
+		recorder: req.Send(),
+	}
+}
+
+//Status Assert the status code
+func (expect *Expectation) Status(code int) *Expectation {
+	expect.t.Helper()
+	if expect.recorder.Code != code {
+		expect.t.Errorf("expected status %d but got %d: %s", code, expect.recorder.Code, expect.recorder.Body.String())
+	}
+	return expect
+}
+
+//Header Assert the header value
+func (expect *Expectation) Header(name string, value string) *Expectation {
+	expect.t.Helper()
+	if actual := expect.recorder.Header().Get(name); actual != value {
+		expect.t.Errorf("expected header %s to be %q but got %q", name, value, actual)
+	}
+	return expect
+}
+
+//Body Assert the whole body
+func (expect *Expectation) Body(body string) *Expectation {
+	expect.t.Helper()
+	if actual := expect.recorder.Body.String(); actual != body {
+		expect.t.Errorf("expected body %q but got %q", body, actual)
+	}
+	return expect
+}
+
+//BodyContains Assert the body contains text
+func (expect *Expectation) BodyContains(text string) *Expectation {
+	expect.t.Helper()
+	if actual := expect.recorder.Body.String(); !strings.Contains(actual, text) {
+		expect.t.Errorf("expected body to contain %q but got %q", text, actual)
+	}
+	return expect
+}
+
+//JSON Unmarshal the JSON body into out
+func (expect *Expectation) JSON(out interface{}) *Expectation {
+	expect.t.Helper()
+	if err := json.Unmarshal(expect.recorder.Body.Bytes(), out); err != nil {
+		expect.t.Errorf("cannot unmarshal body %q: %v", expect.recorder.Body.String(), err)
+	}
+	return expect
+}
+
+//Response The recorded response
+func (expect *Expectation) Response() *httptest.ResponseRecorder {
+	return expect.recorder
+}
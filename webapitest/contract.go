@@ -0,0 +1,29 @@
+package webapitest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-webapi/webapi"
+)
+
+//Contract Report the contract issues of host against the OpenAPI spec file (JSON) via t.Errorf
+func Contract(t testing.TB, host *webapi.Host, specFile string) {
+	t.Helper()
+	file, err := os.Open(specFile)
+	if err != nil {
+		t.Fatalf("cannot open the OpenAPI spec: %v", err)
+	}
+	defer file.Close()
+	spec, err := webapi.LoadOpenAPI(file)
+	if err != nil {
+		t.Fatalf("cannot read the OpenAPI spec: %v", err)
+	}
+	for _, issue := range host.CheckContract(spec) {
+		location := issue.Method + " " + issue.Path
+		if len(issue.Location) > 0 {
+			location += " " + issue.Location
+		}
+		t.Errorf("%s: %s: %s", issue.Kind, location, issue.Message)
+	}
+}
@@ -0,0 +1,134 @@
+package webapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const masked = "<masked>"
+
+var (
+	//Update Rewrite the golden files instead of comparing (go test -update)
+	Update = flag.Bool("update", false, "update the golden files of webapitest")
+
+	//GoldenDir The directory of golden files (relative to the package under test)
+	GoldenDir = "testdata"
+
+	//VolatileHeaders The headers which are masked in snapshots
+	VolatileHeaders = []string{"Date", "Expires", "Last-Modified", "Set-Cookie", "X-Request-Id"}
+)
+
+//Snapshot Capture the response as text: status, sorted headers and the indented JSON body (with sorted keys),
+//the values of volatile headers and the masked names (headers or JSON members at any depth) are replaced
+func Snapshot(recorder *httptest.ResponseRecorder, mask ...string) []byte {
+	headers, members := map[string]bool{}, map[string]bool{}
+	for _, name := range VolatileHeaders {
+		headers[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range mask {
+		headers[http.CanonicalHeaderKey(name)], members[name] = true, true
+	}
+	buffer := &bytes.Buffer{}
+	buffer.WriteString("HTTP " + strconv.Itoa(recorder.Code) + " " + http.StatusText(recorder.Code) + "\n")
+	header := recorder.Header()
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			if headers[name] {
+				value = masked
+			}
+			buffer.WriteString(name + ": " + value + "\n")
+		}
+	}
+	buffer.WriteString("\n")
+	body := recorder.Body.Bytes()
+	var document interface{}
+	if json.Unmarshal(body, &document) == nil {
+		indented := &bytes.Buffer{}
+		encoder := json.NewEncoder(indented)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if encoder.Encode(maskMembers(document, members)) == nil {
+			body = indented.Bytes()
+		}
+	}
+	buffer.Write(body)
+	if len(body) > 0 && body[len(body)-1] != '\n' {
+		buffer.WriteString("\n")
+	}
+	return buffer.Bytes()
+}
+
+//Golden Compare the data with the golden file testdata/<name>.golden, the file will be written with -update
+func Golden(t testing.TB, name string, data []byte) {
+	t.Helper()
+	path := filepath.Join(GoldenDir, filepath.FromSlash(name)+".golden")
+	if *Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("cannot create the directory of golden file: %v", err)
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("cannot write golden file: %v", err)
+		}
+		return
+	}
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("cannot read golden file (run with -update to create it): %v", err)
+		return
+	}
+	if !bytes.Equal(expected, data) {
+		t.Errorf("the snapshot is different from %s at line %d\n--- expected\n%s\n--- actual\n%s", path, diffLine(expected, data), expected, data)
+	}
+}
+
+//Golden Compare the snapshot of response with the golden file (see Snapshot and Golden)
+func (expect *Expectation) Golden(name string, mask ...string) *Expectation {
+	expect.t.Helper()
+	Golden(expect.t, name, Snapshot(expect.recorder, mask...))
+	return expect
+}
+
+//maskMembers replace the values of masked members
+func maskMembers(document interface{}, masks map[string]bool) interface{} {
+	switch value := document.(type) {
+	case map[string]interface{}:
+		for name, member := range value {
+			if masks[name] {
+				value[name] = masked
+			} else {
+				value[name] = maskMembers(member, masks)
+			}
+		}
+	case []interface{}:
+		for index, item := range value {
+			value[index] = maskMembers(item, masks)
+		}
+	}
+	return document
+}
+
+//diffLine the first different line (starts from 1)
+func diffLine(expected []byte, actual []byte) int {
+	left, right := strings.Split(string(expected), "\n"), strings.Split(string(actual), "\n")
+	for index := range left {
+		if index >= len(right) || left[index] != right[index] {
+			return index + 1
+		}
+	}
+	return len(left) + 1
+}
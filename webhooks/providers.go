@@ -0,0 +1,192 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	//Provider Webhook provider which signs requests
+	Provider interface {
+		//Verify Verify the signature of raw body, the timestamp should be checked with now if signed
+		Verify(r *http.Request, body []byte, now time.Time) error
+
+		//Event Get the event type and JSON payload from request
+		Event(r *http.Request, body []byte) (string, []byte, error)
+	}
+
+	//GitHub GitHub webhooks signed with X-Hub-Signature-256
+	GitHub struct {
+		Secret string
+	}
+
+	//Stripe Stripe webhooks signed with Stripe-Signature
+	Stripe struct {
+		Secret string
+
+		//Tolerance The maximum age of signed timestamp, default is 5 minutes
+		Tolerance time.Duration
+	}
+
+	//Slack Slack requests (events, commands and interactions) signed with X-Slack-Signature
+	Slack struct {
+		SigningSecret string
+
+		//Tolerance The maximum age of signed timestamp, default is 5 minutes
+		Tolerance time.Duration
+	}
+)
+
+var (
+	//ErrInvalidSignature The signature is missing or mismatched
+	ErrInvalidSignature = errors.New("webhooks: invalid signature")
+
+	//ErrExpired The signed timestamp is out of replay window
+	ErrExpired = errors.New("webhooks: timestamp is out of tolerance")
+)
+
+const defaultTolerance = 5 * time.Minute
+
+//Verify Verify the HMAC-SHA256 signature of body
+func (provider GitHub) Verify(r *http.Request, body []byte, now time.Time) error {
+	signature := r.Header.Get("X-Hub-Signature-256")
+	if !strings.HasPrefix(signature, "sha256=") || !validMAC(provider.Secret, body, signature[len("sha256="):]) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+//Event The event is read from X-GitHub-Event header
+func (provider GitHub) Event(r *http.Request, body []byte) (string, []byte, error) {
+	event := r.Header.Get("X-GitHub-Event")
+	if len(event) == 0 {
+		return "", nil, errors.New("webhooks: X-GitHub-Event is missing")
+	}
+	return event, body, nil
+}
+
+//Verify Verify the v1 signatures of timestamp and body
+func (provider Stripe) Verify(r *http.Request, body []byte, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, item := range strings.Split(r.Header.Get("Stripe-Signature"), ",") {
+		pair := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+		switch pair[0] {
+		case "t":
+			timestamp = pair[1]
+		case "v1":
+			signatures = append(signatures, pair[1])
+		}
+	}
+	if len(timestamp) == 0 || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if err := checkTimestamp(timestamp, now, provider.Tolerance); err != nil {
+		return err
+	}
+	payload := append([]byte(timestamp+"."), body...)
+	for _, signature := range signatures {
+		if validMAC(provider.Secret, payload, signature) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+//Event The event is read from the "type" field of body
+func (provider Stripe) Event(r *http.Request, body []byte) (string, []byte, error) {
+	var event struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return "", nil, err
+	}
+	return event.Type, body, nil
+}
+
+//Verify Verify the v0 signature of timestamp and body
+func (provider Slack) Verify(r *http.Request, body []byte, now time.Time) error {
+	timestamp, signature := r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature")
+	if len(timestamp) == 0 || !strings.HasPrefix(signature, "v0=") {
+		return ErrInvalidSignature
+	}
+	if err := checkTimestamp(timestamp, now, provider.Tolerance); err != nil {
+		return err
+	}
+	if !validMAC(provider.SigningSecret, append([]byte("v0:"+timestamp+":"), body...), signature[len("v0="):]) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+//Event The event is the type of event callback (e.g. app_mention), interaction (e.g. block_actions),
+//"url_verification" or "command" (the form is converted into JSON object for slash commands)
+func (provider Slack) Event(r *http.Request, body []byte) (string, []byte, error) {
+	if strings.HasPrefix(strings.ToLower(r.Header.Get("Content-Type")), "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "", nil, err
+		}
+		if payload := form.Get("payload"); len(payload) > 0 {
+			//interactive components
+			body = []byte(payload)
+		} else {
+			fields := map[string]string{}
+			for name := range form {
+				fields[name] = form.Get(name)
+			}
+			data, err := json.Marshal(fields)
+			return "command", data, err
+		}
+	}
+	var event struct {
+		Type  string `json:"type"`
+		Event struct {
+			Type string `json:"type"`
+		} `json:"event"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return "", nil, err
+	}
+	if event.Type == "event_callback" && len(event.Event.Type) > 0 {
+		return event.Event.Type, body, nil
+	}
+	return event.Type, body, nil
+}
+
+//validMAC compare the hex HMAC-SHA256 in constant time
+func validMAC(secret string, payload []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(secret) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+//checkTimestamp check the unix timestamp is in replay window
+func checkTimestamp(timestamp string, now time.Time, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if tolerance <= 0 {
+		tolerance = defaultTolerance
+	}
+	if diff := now.Sub(time.Unix(seconds, 0)); diff > tolerance || diff < -tolerance {
+		return ErrExpired
+	}
+	return nil
+}
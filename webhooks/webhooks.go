@@ -0,0 +1,139 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/go-webapi/webapi"
+)
+
+type (
+	//Receiver Verify webhook requests and dispatch the events to typed handlers
+	Receiver struct {
+		provider Provider
+		handlers map[string]reflect.Value
+		errList  []error
+
+		//Now The clock of replay window, default is time.Now
+		Now func() time.Time
+	}
+)
+
+var (
+	contextType = reflect.TypeOf(&webapi.Context{})
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+//NewReceiver Create the receiver of provider
+func NewReceiver(provider Provider) *Receiver {
+	return &Receiver{
+		provider: provider,
+		handlers: map[string]reflect.Value{},
+		Now:      time.Now,
+	}
+}
+
+//On Register the handler of event ("*" for the events without handler),
+//the handler should be declared as func(*webapi.Context, *Payload) error (or func(*webapi.Context) error),
+//the payload will be unmarshaled from JSON
+func (recv *Receiver) On(event string, handler interface{}) *Receiver {
+	value := reflect.ValueOf(handler)
+	typ := value.Type()
+	if typ.Kind() != reflect.Func || typ.NumIn() < 1 || typ.NumIn() > 2 || typ.In(0) != contextType || typ.NumOut() != 1 || typ.Out(0) != errorType {
+		recv.errList = append(recv.errList, errors.New("webhooks: the handler of "+event+" should be func(*webapi.Context, *Payload) error"))
+		return recv
+	}
+	recv.handlers[event] = value
+	return recv
+}
+
+//Register Register the receiver as POST endpoint
+func (recv *Receiver) Register(host *webapi.Host, path string, middlewares ...webapi.Middleware) error {
+	if len(recv.errList) > 0 {
+		return recv.errList[0]
+	}
+	return host.AddEndpoint(http.MethodPost, path, recv.Serve, middlewares...)
+}
+
+//Serve Verify the signature of raw body (before BeforeReading) and dispatch the event,
+//"204 No Content" will be replied if the handler did not reply
+func (recv *Receiver) Serve(ctx *webapi.Context) {
+	if !recv.verify(ctx) {
+		return
+	}
+	event, payload, err := recv.provider.Event(ctx.GetRequest(), ctx.Body())
+	if err != nil {
+		ctx.ReplyError(http.StatusBadRequest, err)
+		return
+	}
+	handler, existed := recv.handlers[event]
+	if !existed {
+		if handler, existed = recv.handlers["*"]; !existed {
+			//the unknown events should be acknowledged, otherwise they will be redelivered
+			ctx.Reply(http.StatusNoContent)
+			return
+		}
+	}
+	args := []reflect.Value{reflect.ValueOf(ctx)}
+	if handler.Type().NumIn() == 2 {
+		typ := handler.Type().In(1)
+		arg := reflect.New(typ)
+		if typ.Kind() == reflect.Ptr {
+			arg.Elem().Set(reflect.New(typ.Elem()))
+		}
+		if err = json.Unmarshal(payload, arg.Interface()); err != nil {
+			ctx.ReplyError(http.StatusBadRequest, err)
+			return
+		}
+		args = append(args, arg.Elem())
+	}
+	if err, _ = handler.Call(args)[0].Interface().(error); err != nil {
+		status := http.StatusInternalServerError
+		var httperr *webapi.HTTPError
+		if errors.As(err, &httperr) && httperr.Status > 0 {
+			status = httperr.Status
+		}
+		ctx.ReplyError(status, err)
+		return
+	}
+	if ctx.StatusCode() == 0 {
+		ctx.Reply(http.StatusNoContent)
+	}
+}
+
+//Verifier The middleware which only verifies the signature (e.g. for controllers),
+//the buffered body can still be bound by the controller
+func (recv *Receiver) Verifier() webapi.Middleware {
+	return verifier{recv}
+}
+
+//verify verify the raw body and reply the error if failed
+func (recv *Receiver) verify(ctx *webapi.Context) bool {
+	body := ctx.Body()
+	if err := ctx.BodyError(); err != nil {
+		if errors.Is(err, webapi.ErrBodyTooLarge) {
+			ctx.ReplyError(http.StatusRequestEntityTooLarge, err)
+		} else {
+			ctx.ReplyError(http.StatusBadRequest, err)
+		}
+		return false
+	}
+	if err := recv.provider.Verify(ctx.GetRequest(), body, recv.Now()); err != nil {
+		ctx.ReplyError(http.StatusUnauthorized, err)
+		return false
+	}
+	return true
+}
+
+type verifier struct {
+	recv *Receiver
+}
+
+func (v verifier) Invoke(ctx *webapi.Context, next webapi.HTTPHandler) {
+	if v.recv.verify(ctx) {
+		next(ctx)
+	}
+}
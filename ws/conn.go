@@ -0,0 +1,362 @@
+package ws
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-webapi/webapi"
+)
+
+type (
+	//Conn WebSocket connection managed by manager
+	Conn struct {
+		//ID Unique identifier of connection
+		ID string
+
+		manager     *Manager
+		conn        net.Conn
+		reader      *bufio.Reader
+		writer      *bufio.Writer
+		request     *http.Request
+		subprotocol string
+
+		writeMu sync.Mutex
+		queue   chan outgoing
+		closing chan struct{}
+		done    chan struct{}
+		once    sync.Once
+		code    int
+		reason  string
+
+		mu    sync.Mutex
+		rooms map[string]struct{}
+	}
+
+	outgoing struct {
+		opcode MessageType
+		data   []byte
+	}
+)
+
+var (
+	//ErrClosed The connection has been closed
+	ErrClosed = errors.New("websocket: connection closed")
+
+	//ErrBackpressure The send queue of connection is still full after SendTimeout,
+	//the connection will be closed as a slow consumer
+	ErrBackpressure = errors.New("websocket: send queue overflow")
+)
+
+//Request The HTTP request of handshake
+func (c *Conn) Request() *http.Request {
+	return c.request
+}
+
+//Subprotocol The negotiated subprotocol
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+//Join Join the room
+func (c *Conn) Join(room string) {
+	c.manager.join(c, room)
+}
+
+//Leave Leave the room
+func (c *Conn) Leave(room string) {
+	c.manager.leave(c, room)
+}
+
+//Rooms The rooms joined
+func (c *Conn) Rooms() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+//Send Marshal the message with serializer and put it into send queue
+func (c *Conn) Send(msg interface{}) error {
+	opcode, data, err := c.manager.marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.SendMessage(opcode, data)
+}
+
+//SendMessage Put the raw message into send queue, it waits for SendTimeout if the queue is full
+func (c *Conn) SendMessage(opcode MessageType, data []byte) error {
+	if sent, err := c.enqueue(outgoing{opcode, data}); sent || err != nil {
+		return err
+	}
+	return c.wait(outgoing{opcode, data})
+}
+
+//Receive Read the next data message and unmarshal it with serializer
+func (c *Conn) Receive(v interface{}) error {
+	_, data, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return c.manager.opts.Serializer.Unmarshal(data, v)
+}
+
+//ReadMessage Read the next data message, control frames are handled automatically.
+//*CloseError will be returned if the peer closed the connection.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var opcode MessageType
+	var message []byte
+	for {
+		c.extendDeadline()
+		f, err := readFrame(c.reader, c.manager.opts.MaxMessageSize)
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+		switch f.opcode {
+		case PingMessage:
+			if err = c.write(PongMessage, f.payload); err != nil {
+				return 0, nil, c.fail(err)
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			closeErr, err := parseClose(f.payload)
+			if err != nil {
+				return 0, nil, c.fail(err)
+			}
+			c.shutdown(closeErr.Code, "")
+			<-c.done
+			return 0, nil, closeErr
+		case continuationFrame:
+			if opcode == 0 {
+				return 0, nil, c.fail(errProtocol)
+			}
+		default:
+			if opcode != 0 {
+				//the previous message is not finished
+				return 0, nil, c.fail(errProtocol)
+			}
+			opcode = f.opcode
+		}
+		if limit := c.manager.opts.MaxMessageSize; limit > 0 && int64(len(message)+len(f.payload)) > limit {
+			return 0, nil, c.fail(ErrMessageTooBig)
+		}
+		message = append(message, f.payload...)
+		if f.fin {
+			if opcode == TextMessage && !utf8.Valid(message) {
+				return 0, nil, c.fail(errInvalidText)
+			}
+			return opcode, message, nil
+		}
+	}
+}
+
+//Close Close the connection normally
+func (c *Conn) Close() error {
+	return c.CloseWith(CloseNormalClosure, "")
+}
+
+//CloseWith Send the queued messages and close frame, then close the connection
+func (c *Conn) CloseWith(code int, reason string) error {
+	c.shutdown(code, reason)
+	<-c.done
+	return nil
+}
+
+//Done The channel closed when connection is closed
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+//shutdown ask the writer to close connection
+func (c *Conn) shutdown(code int, reason string) {
+	c.once.Do(func() {
+		c.code, c.reason = code, reason
+		close(c.closing)
+	})
+}
+
+//fail close the connection with the status code of error
+func (c *Conn) fail(err error) error {
+	switch err {
+	case errProtocol:
+		c.shutdown(CloseProtocolError, "")
+	case ErrMessageTooBig:
+		c.shutdown(CloseMessageTooBig, "")
+	case errInvalidText:
+		c.shutdown(CloseInvalidPayload, "")
+	default:
+		//the connection is broken
+		c.conn.Close()
+		c.shutdown(CloseGoingAway, "")
+	}
+	return err
+}
+
+//enqueue put the message into queue without blocking
+func (c *Conn) enqueue(msg outgoing) (bool, error) {
+	select {
+	case <-c.closing:
+		return false, ErrClosed
+	default:
+	}
+	select {
+	case c.queue <- msg:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+//wait wait for the queue to be available until SendTimeout
+func (c *Conn) wait(msg outgoing) error {
+	if timeout := c.manager.opts.SendTimeout; timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case c.queue <- msg:
+			return nil
+		case <-c.closing:
+			return ErrClosed
+		case <-timer.C:
+		}
+	}
+	c.shutdown(ClosePolicyViolation, "slow consumer")
+	return ErrBackpressure
+}
+
+//write write a frame with deadline
+func (c *Conn) write(opcode MessageType, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if timeout := c.manager.opts.WriteTimeout; timeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(timeout))
+	}
+	return writeFrame(c.writer, opcode, data)
+}
+
+//extendDeadline the peer should reply pong in time
+func (c *Conn) extendDeadline() {
+	if interval := c.manager.opts.PingInterval; interval > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(interval * 2))
+	}
+}
+
+//writeLoop send queued messages and pings, the connection will be closed until shutdown or broken
+func (c *Conn) writeLoop() {
+	defer func() {
+		c.conn.Close()
+		c.manager.unregister(c)
+		close(c.done)
+	}()
+	var ping <-chan time.Time
+	if interval := c.manager.opts.PingInterval; interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+	for {
+		select {
+		case msg := <-c.queue:
+			if err := c.write(msg.opcode, msg.data); err != nil {
+				return
+			}
+		case <-ping:
+			if err := c.write(PingMessage, nil); err != nil {
+				return
+			}
+		case <-c.closing:
+			if c.drain() == nil {
+				c.write(CloseMessage, closePayload(c.code, c.reason))
+			}
+			return
+		}
+	}
+}
+
+//drain send the rest messages of queue before closing
+func (c *Conn) drain() error {
+	for {
+		select {
+		case msg := <-c.queue:
+			if err := c.write(msg.opcode, msg.data); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+//accept complete the handshake and take over the connection
+func accept(m *Manager, ctx *webapi.Context) (*Conn, int, error) {
+	r := ctx.GetRequest()
+	switch {
+	case r.Method != http.MethodGet:
+		return nil, http.StatusMethodNotAllowed, errors.New("websocket: the method of handshake should be GET")
+	case !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket"):
+		return nil, http.StatusBadRequest, errors.New("websocket: not a websocket handshake")
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		ctx.ResponseHeader().Set("Sec-WebSocket-Version", "13")
+		return nil, http.StatusUpgradeRequired, errors.New("websocket: unsupported version")
+	case !m.opts.CheckOrigin(r):
+		return nil, http.StatusForbidden, errors.New("websocket: origin is not allowed")
+	}
+	key := strings.TrimSpace(r.Header.Get("Sec-WebSocket-Key"))
+	if len(key) == 0 {
+		return nil, http.StatusBadRequest, errors.New("websocket: Sec-WebSocket-Key is missing")
+	}
+	subprotocol := m.subprotocol(r)
+	netconn, rw, err := ctx.Hijack()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	//the timeouts of http server should not be applied to websocket
+	netconn.SetDeadline(time.Time{})
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " + acceptKey(key) + "\r\n"
+	if len(subprotocol) > 0 {
+		response += "Sec-WebSocket-Protocol: " + subprotocol + "\r\n"
+	}
+	if _, err = rw.WriteString(response + "\r\n"); err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		netconn.Close()
+		return nil, 0, err
+	}
+	return &Conn{
+		ID:          newID(),
+		manager:     m,
+		conn:        netconn,
+		reader:      rw.Reader,
+		writer:      rw.Writer,
+		request:     r,
+		subprotocol: subprotocol,
+		queue:       make(chan outgoing, m.opts.SendQueue),
+		closing:     make(chan struct{}),
+		done:        make(chan struct{}),
+		rooms:       map[string]struct{}{},
+	}, 0, nil
+}
+
+//headerContains check the comma separated tokens of header
+func headerContains(header http.Header, name string, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package ws
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+//MessageType The opcode of frame
+type MessageType int
+
+const (
+	continuationFrame MessageType = 0
+	//TextMessage Text data frame (UTF-8)
+	TextMessage MessageType = 1
+	//BinaryMessage Binary data frame
+	BinaryMessage MessageType = 2
+	//CloseMessage Close control frame
+	CloseMessage MessageType = 8
+	//PingMessage Ping control frame
+	PingMessage MessageType = 9
+	//PongMessage Pong control frame
+	PongMessage MessageType = 10
+)
+
+//Close codes defined in RFC 6455
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseUnsupportedData  = 1003
+	CloseNoStatusReceived = 1005
+	CloseInvalidPayload   = 1007
+	ClosePolicyViolation  = 1008
+	CloseMessageTooBig    = 1009
+	CloseInternalError    = 1011
+)
+
+var (
+	//ErrMessageTooBig The message is larger than MaxMessageSize
+	ErrMessageTooBig = errors.New("websocket: message too big")
+
+	errProtocol    = errors.New("websocket: protocol error")
+	errInvalidText = errors.New("websocket: invalid utf-8 text")
+)
+
+//CloseError The close frame received from peer
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (err *CloseError) Error() string {
+	return "websocket: closed with code " + strconv.Itoa(err.Code) + " " + err.Reason
+}
+
+type frame struct {
+	fin     bool
+	opcode  MessageType
+	payload []byte
+}
+
+func (t MessageType) isControl() bool {
+	return t >= CloseMessage
+}
+
+//readFrame read a frame from client, the frames of client must be masked
+func readFrame(r *bufio.Reader, limit int64) (*frame, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	f := &frame{
+		fin:    header[0]&0x80 != 0,
+		opcode: MessageType(header[0] & 0x0f),
+	}
+	if header[0]&0x70 != 0 || header[1]&0x80 == 0 {
+		//no extension is negotiated and client frames must be masked
+		return nil, errProtocol
+	}
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return nil, err
+		}
+		if length = int64(binary.BigEndian.Uint64(ext[:])); length < 0 {
+			return nil, errProtocol
+		}
+	}
+	switch f.opcode {
+	case continuationFrame, TextMessage, BinaryMessage:
+	case CloseMessage, PingMessage, PongMessage:
+		if !f.fin || length > 125 {
+			return nil, errProtocol
+		}
+	default:
+		return nil, errProtocol
+	}
+	if limit > 0 && length > limit {
+		return nil, ErrMessageTooBig
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return nil, err
+	}
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return nil, err
+	}
+	for index := range f.payload {
+		f.payload[index] ^= mask[index%4]
+	}
+	return f, nil
+}
+
+//writeFrame write an unfragmented and unmasked frame (server side)
+func writeFrame(w *bufio.Writer, opcode MessageType, payload []byte) error {
+	header := []byte{0x80 | byte(opcode), 0}
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = append(header, byte(length>>8), byte(length))
+	default:
+		header[1] = 127
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(length))
+		header = append(header, ext[:]...)
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+//closePayload build the payload of close frame
+func closePayload(code int, reason string) []byte {
+	if code == CloseNoStatusReceived {
+		return nil
+	}
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	return append([]byte{byte(code >> 8), byte(code)}, reason...)
+}
+
+//parseClose parse the payload of close frame
+func parseClose(payload []byte) (*CloseError, error) {
+	switch {
+	case len(payload) == 0:
+		return &CloseError{Code: CloseNoStatusReceived}, nil
+	case len(payload) == 1:
+		return nil, errProtocol
+	}
+	err := &CloseError{
+		Code:   int(binary.BigEndian.Uint16(payload)),
+		Reason: string(payload[2:]),
+	}
+	if !utf8.ValidString(err.Reason) {
+		return nil, errInvalidText
+	}
+	return err, nil
+}
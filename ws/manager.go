@@ -0,0 +1,340 @@
+package ws
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-webapi/webapi"
+)
+
+type (
+	//Options Options of manager
+	Options struct {
+		//Serializer The serializer of typed messages, default is JSON
+		Serializer webapi.Serializer
+
+		//Subprotocols The supported subprotocols in order of preference
+		Subprotocols []string
+
+		//CheckOrigin Check the Origin header of handshake, the same host is required by default
+		CheckOrigin func(r *http.Request) bool
+
+		//SendQueue The size of send queue per connection, default is 64
+		SendQueue int
+
+		//SendTimeout The maximum duration to wait while the send queue is full, default is 5s.
+		//The connection will be closed as a slow consumer after that, negative means no waiting.
+		SendTimeout time.Duration
+
+		//WriteTimeout The timeout of writing a frame, default is 10s
+		WriteTimeout time.Duration
+
+		//PingInterval The interval of ping, the connection will be closed if nothing received in twice of it.
+		//Default is 30s, negative means no ping.
+		PingInterval time.Duration
+
+		//MaxMessageSize The maximum bytes of received message, default is 1MB
+		MaxMessageSize int64
+	}
+
+	//Manager Registry of websocket connections with rooms
+	Manager struct {
+		opts   Options
+		mu     sync.RWMutex
+		conns  map[string]*Conn
+		rooms  map[string]map[string]*Conn
+		closed bool
+		wg     sync.WaitGroup
+	}
+)
+
+//websocketGUID The GUID used in handshake (RFC 6455)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+//ErrManagerClosed The manager has been shut down
+var ErrManagerClosed = errors.New("websocket: manager closed")
+
+//NewManager Create the connection manager, the connections will be closed gracefully while the host is shutting down
+func NewManager(host *webapi.Host, opts Options) *Manager {
+	if opts.Serializer == nil {
+		opts.Serializer = webapi.Serializers["application/json"]
+	}
+	if opts.CheckOrigin == nil {
+		opts.CheckOrigin = sameOrigin
+	}
+	if opts.SendQueue <= 0 {
+		opts.SendQueue = 64
+	}
+	if opts.SendTimeout == 0 {
+		opts.SendTimeout = 5 * time.Second
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = 10 * time.Second
+	}
+	if opts.PingInterval == 0 {
+		opts.PingInterval = 30 * time.Second
+	}
+	if opts.MaxMessageSize <= 0 {
+		opts.MaxMessageSize = 1 << 20
+	}
+	m := &Manager{
+		opts:  opts,
+		conns: map[string]*Conn{},
+		rooms: map[string]map[string]*Conn{},
+	}
+	if host != nil {
+		host.OnStop(m.Shutdown)
+	}
+	return m
+}
+
+//Handler Create the endpoint handler which upgrades the request and serves the connection,
+//the connection will be closed after handler returned
+func (m *Manager) Handler(handler func(conn *Conn)) webapi.HTTPHandler {
+	return func(ctx *webapi.Context) {
+		conn, err := m.Upgrade(ctx)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}
+}
+
+//Upgrade Upgrade the request and register the connection, the error will be replied if failed
+func (m *Manager) Upgrade(ctx *webapi.Context) (*Conn, error) {
+	m.mu.RLock()
+	closed := m.closed
+	m.mu.RUnlock()
+	if closed {
+		ctx.ReplyError(http.StatusServiceUnavailable, ErrManagerClosed)
+		return nil, ErrManagerClosed
+	}
+	conn, status, err := accept(m, ctx)
+	if err != nil {
+		if status != 0 {
+			ctx.ReplyError(status, err)
+		}
+		return nil, err
+	}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		conn.conn.Close()
+		return nil, ErrManagerClosed
+	}
+	m.conns[conn.ID] = conn
+	m.wg.Add(1)
+	m.mu.Unlock()
+	go conn.writeLoop()
+	return conn, nil
+}
+
+//Get Get the connection by id
+func (m *Manager) Get(id string) *Conn {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.conns[id]
+}
+
+//Count The count of connections
+func (m *Manager) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.conns)
+}
+
+//Room The connections in room
+func (m *Manager) Room(room string) []*Conn {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	conns := make([]*Conn, 0, len(m.rooms[room]))
+	for _, conn := range m.rooms[room] {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+//Send Send message to the connection by id
+func (m *Manager) Send(id string, msg interface{}) error {
+	conn := m.Get(id)
+	if conn == nil {
+		return ErrClosed
+	}
+	return conn.Send(msg)
+}
+
+//Broadcast Send message to all connections in room (all connections if room is empty),
+//the slow consumers will be closed and the others will not be blocked
+func (m *Manager) Broadcast(room string, msg interface{}) error {
+	opcode, data, err := m.marshal(msg)
+	if err != nil {
+		return err
+	}
+	m.mu.RLock()
+	targets := m.conns
+	if len(room) > 0 {
+		targets = m.rooms[room]
+	}
+	conns := make([]*Conn, 0, len(targets))
+	for _, conn := range targets {
+		conns = append(conns, conn)
+	}
+	m.mu.RUnlock()
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		if sent, err := conn.enqueue(outgoing{opcode, data}); sent || err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(conn *Conn) {
+			defer wg.Done()
+			conn.wait(outgoing{opcode, data})
+		}(conn)
+	}
+	wg.Wait()
+	return nil
+}
+
+//Shutdown Close all connections with going away and wait until they are closed
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	conns := make([]*Conn, 0, len(m.conns))
+	for _, conn := range m.conns {
+		conns = append(conns, conn)
+	}
+	m.mu.Unlock()
+	for _, conn := range conns {
+		conn.shutdown(CloseGoingAway, "server shutdown")
+	}
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, conn := range conns {
+			conn.conn.Close()
+		}
+		return ctx.Err()
+	}
+}
+
+//marshal marshal the message with serializer, text frame will be used for textual content types
+func (m *Manager) marshal(msg interface{}) (MessageType, []byte, error) {
+	if data, isBytes := msg.([]byte); isBytes {
+		return BinaryMessage, data, nil
+	}
+	data, err := m.opts.Serializer.Marshal(msg)
+	if err != nil {
+		return 0, nil, err
+	}
+	contentType := strings.ToLower(m.opts.Serializer.ContentType())
+	if (strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "json") || strings.Contains(contentType, "xml")) && utf8.Valid(data) {
+		return TextMessage, data, nil
+	}
+	return BinaryMessage, data, nil
+}
+
+func (m *Manager) join(conn *Conn, room string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, registered := m.conns[conn.ID]; !registered {
+		return
+	}
+	members, existed := m.rooms[room]
+	if !existed {
+		members = map[string]*Conn{}
+		m.rooms[room] = members
+	}
+	members[conn.ID] = conn
+	conn.mu.Lock()
+	conn.rooms[room] = struct{}{}
+	conn.mu.Unlock()
+}
+
+func (m *Manager) leave(conn *Conn, room string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if members := m.rooms[room]; members != nil {
+		delete(members, conn.ID)
+		if len(members) == 0 {
+			delete(m.rooms, room)
+		}
+	}
+	conn.mu.Lock()
+	delete(conn.rooms, room)
+	conn.mu.Unlock()
+}
+
+//unregister remove the closed connection from registry and rooms
+func (m *Manager) unregister(conn *Conn) {
+	m.mu.Lock()
+	delete(m.conns, conn.ID)
+	conn.mu.Lock()
+	for room := range conn.rooms {
+		if members := m.rooms[room]; members != nil {
+			delete(members, conn.ID)
+			if len(members) == 0 {
+				delete(m.rooms, room)
+			}
+		}
+	}
+	conn.rooms = map[string]struct{}{}
+	conn.mu.Unlock()
+	m.mu.Unlock()
+	m.wg.Done()
+}
+
+//subprotocol select the most preferred subprotocol requested by client
+func (m *Manager) subprotocol(r *http.Request) string {
+	var requested []string
+	for _, value := range r.Header[http.CanonicalHeaderKey("Sec-WebSocket-Protocol")] {
+		for _, item := range strings.Split(value, ",") {
+			requested = append(requested, strings.TrimSpace(item))
+		}
+	}
+	for _, supported := range m.opts.Subprotocols {
+		for _, item := range requested {
+			if item == supported {
+				return supported
+			}
+		}
+	}
+	return ""
+}
+
+//sameOrigin check the host of Origin header (requests without Origin are allowed)
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func newID() string {
+	var buf [16]byte
+	rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}